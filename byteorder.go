@@ -0,0 +1,59 @@
+package murmur3
+
+// The SumBE and SumLE methods below have the method set of the v2
+// murmur3.ByteOrderSummer interface.
+
+// SumBE appends the big endian sum to b. It is the same as Sum.
+func (d Digest32) SumBE(b []byte) []byte { return d.Sum(b) }
+
+// SumLE appends the little endian sum to b, the byte order of the C
+// reference output.
+func (d Digest32) SumLE(b []byte) []byte { return appendLE32(b, d.Sum32()) }
+
+// SumBE appends the big endian sum to b. It is the same as Sum.
+func (d Digest64) SumBE(b []byte) []byte { return d.Sum(b) }
+
+// SumLE appends the little endian sum to b, the byte order of the first half
+// of the C reference output.
+func (d Digest64) SumLE(b []byte) []byte { return appendLE64(b, d.Sum64()) }
+
+// SumBE appends the big endian sum, h1 then h2, to b. It is the same as Sum.
+func (d Digest128) SumBE(b []byte) []byte { return d.Sum(b) }
+
+// SumLE appends h1 then h2 to b, each little endian, the byte order of the C
+// reference output.
+func (d Digest128) SumLE(b []byte) []byte {
+	h1, h2 := d.Sum128()
+	return appendLE64(appendLE64(b, h1), h2)
+}
+
+// AppendCanonical32 appends Sum32WithSeed(data, seed) to dst in the byte
+// order of the C reference MurmurHash3_x86_32 output.
+func AppendCanonical32(dst, data []byte, seed uint32) []byte {
+	return appendLE32(dst, Sum32WithSeed(data, seed))
+}
+
+// AppendCanonical64 appends Sum64WithSeed(data, seed) to dst in the byte
+// order of the first half of the C reference MurmurHash3_x64_128 output.
+func AppendCanonical64(dst, data []byte, seed uint32) []byte {
+	return appendLE64(dst, Sum64WithSeed(data, seed))
+}
+
+// AppendCanonical128 appends Sum128WithSeed(data, seed) to dst in the byte
+// order of the C reference MurmurHash3_x64_128 output.
+func AppendCanonical128(dst, data []byte, seed uint32) []byte {
+	h1, h2 := Sum128WithSeed(data, seed)
+	return appendLE64(appendLE64(dst, h1), h2)
+}
+
+// appendLE32 and appendLE64 are binary.LittleEndian.AppendUint32 and
+// AppendUint64, which need Go 1.19.
+func appendLE32(b []byte, v uint32) []byte {
+	return append(b, byte(v), byte(v>>8), byte(v>>16), byte(v>>24))
+}
+
+func appendLE64(b []byte, v uint64) []byte {
+	return append(b,
+		byte(v), byte(v>>8), byte(v>>16), byte(v>>24),
+		byte(v>>32), byte(v>>40), byte(v>>48), byte(v>>56))
+}
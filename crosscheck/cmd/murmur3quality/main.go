@@ -0,0 +1,107 @@
+// Command murmur3quality runs the SMHasher-style quality suite from
+// github.com/m3db/stackmurmur3/v2/quality against every murmur3
+// implementation in this repository: the v1 sums and value digests, the v2
+// sums, and the v2 stackmurmur3 digests. It lives in the crosscheck module,
+// the only one that depends on both v1 and v2; run it from there with
+// go run ./cmd/murmur3quality.
+//
+// Usage:
+//
+//	murmur3quality [-quick] [-hash substring] [-v]
+//
+// Hash names start with v1., v2. or stackmurmur3., so for example -hash v1.
+// selects the v1 implementations. It exits non-zero if any test fails.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	murmur3 "github.com/m3db/stackmurmur3"
+	"github.com/m3db/stackmurmur3/v2/quality"
+)
+
+var (
+	quick   = flag.Bool("quick", false, "use the small unit test configuration")
+	filter  = flag.String("hash", "", "only test hashes whose name contains this substring")
+	verbose = flag.Bool("v", false, "print passing tests too")
+)
+
+func main() {
+	flag.Parse()
+
+	cfg := quality.DefaultConfig()
+	if *quick {
+		cfg = quality.QuickConfig()
+	}
+
+	hashes := append(v1Hashes(), quality.Hashes()...)
+	failed := 0
+	for _, h := range hashes {
+		if !strings.Contains(h.Name, *filter) {
+			continue
+		}
+		for _, r := range quality.Run(h, cfg) {
+			if !r.Pass {
+				failed++
+			}
+			if !r.Pass || *verbose {
+				fmt.Println(r)
+			}
+		}
+	}
+	if failed > 0 {
+		fmt.Printf("%d tests failed\n", failed)
+		os.Exit(1)
+	}
+	fmt.Println("all tests passed")
+}
+
+// v1Hashes adapts the v1 package, whose sums take a uint32 seed like the
+// canonical implementation. The digests are fed in two writes to exercise
+// their buffering, as quality.Hashes does for the v2 digests.
+func v1Hashes() []quality.Hash {
+	return []quality.Hash{
+		{
+			Name: "v1.Sum32WithSeed", Bits: 32, Verification: quality.VerificationX86_32,
+			Sum: func(seed uint32, key []byte) (uint64, uint64) {
+				return uint64(murmur3.Sum32WithSeed(key, seed)), 0
+			},
+		},
+		{
+			Name: "v1.Sum64WithSeed", Bits: 64,
+			Sum: func(seed uint32, key []byte) (uint64, uint64) {
+				return murmur3.Sum64WithSeed(key, seed), 0
+			},
+		},
+		{
+			Name: "v1.Sum128WithSeed", Bits: 128, Verification: quality.VerificationX64_128,
+			Sum: func(seed uint32, key []byte) (uint64, uint64) {
+				return murmur3.Sum128WithSeed(key, seed)
+			},
+		},
+		{
+			Name: "v1.Digest32", Bits: 32, Verification: quality.VerificationX86_32,
+			Sum: func(seed uint32, key []byte) (uint64, uint64) {
+				d := murmur3.New32WithSeed(seed).Write(key[:len(key)/2]).Write(key[len(key)/2:])
+				return uint64(d.Sum32()), 0
+			},
+		},
+		{
+			Name: "v1.Digest64", Bits: 64,
+			Sum: func(seed uint32, key []byte) (uint64, uint64) {
+				d := murmur3.New64WithSeed(seed).Write(key[:len(key)/2]).Write(key[len(key)/2:])
+				return d.Sum64(), 0
+			},
+		},
+		{
+			Name: "v1.Digest128", Bits: 128, Verification: quality.VerificationX64_128,
+			Sum: func(seed uint32, key []byte) (uint64, uint64) {
+				d := murmur3.New128WithSeed(seed).Write(key[:len(key)/2]).Write(key[len(key)/2:])
+				return d.Sum128()
+			},
+		},
+	}
+}
@@ -0,0 +1,141 @@
+package crosscheck
+
+import (
+	"math/rand"
+	"testing"
+	"testing/quick"
+
+	v1 "github.com/m3db/stackmurmur3"
+	murmur3 "github.com/m3db/stackmurmur3/v2"
+	"github.com/m3db/stackmurmur3/v2/stackmurmur3"
+	"github.com/m3db/stackmurmur3/v2/testdata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Make sure interfaces are correctly implemented.
+var (
+	_ murmur3.Streaming128    = new(v1.Stream128)
+	_ murmur3.OneShot128      = v1.Seed128(0)
+	_ murmur3.ByteOrderSummer = v1.Digest32{}
+	_ murmur3.ByteOrderSummer = v1.Digest64{}
+	_ murmur3.ByteOrderSummer = v1.Digest128{}
+)
+
+// seeds are the v1 seeds checked for every input. A v1 seed seeds both
+// halves of the v2 128 bit state.
+var seeds = []uint32{0, 1, 0x2a, 0x9747b28c, 0xffffffff}
+
+func TestSums(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	buf := testdata.RandBytes(300)
+	for i := 0; i < 200; i++ {
+		data := buf[:rnd.Intn(len(buf)+1)]
+		for _, seed := range append(seeds, rnd.Uint32()) {
+			s := uint64(seed)
+			assert.Equal(t, murmur3.SeedSum32(seed, data), v1.Sum32WithSeed(data, seed))
+			assert.Equal(t, murmur3.SeedSum64(s, data), v1.Sum64WithSeed(data, seed))
+			w1, w2 := murmur3.SeedSum128(s, s, data)
+			h1, h2 := v1.Sum128WithSeed(data, seed)
+			require.Equal(t, [2]uint64{w1, w2}, [2]uint64{h1, h2}, "seed %x, %d bytes", seed, len(data))
+			assert.Equal(t, murmur3.SeedSumUint128(s, s, data), murmur3.Uint128(v1.SumUint128WithSeed(data, seed)))
+
+			assert.Equal(t, murmur3.AppendCanonical32(nil, seed, data), v1.AppendCanonical32(nil, data, seed))
+			assert.Equal(t, murmur3.AppendCanonical64(nil, s, data), v1.AppendCanonical64(nil, data, seed))
+			assert.Equal(t, murmur3.AppendCanonical128(nil, s, s, data), v1.AppendCanonical128(nil, data, seed))
+		}
+
+		assert.Equal(t, murmur3.Sum32(data), v1.Sum32(data))
+		assert.Equal(t, murmur3.Sum64(data), v1.Sum64(data))
+		w1, w2 := murmur3.Sum128(data)
+		h1, h2 := v1.Sum128(data)
+		assert.Equal(t, [2]uint64{w1, w2}, [2]uint64{h1, h2})
+		assert.Equal(t, murmur3.SumUint128(data), murmur3.Uint128(v1.SumUint128(data)))
+	}
+}
+
+// TestDigests writes the same inputs, in random chunks, to the v1 value
+// digests, the v2 hash.Hash digests and the stackmurmur3 digests.
+func TestDigests(t *testing.T) {
+	rnd := rand.New(rand.NewSource(2))
+	buf := testdata.RandBytes(300)
+	for i := 0; i < 200; i++ {
+		data := buf[:rnd.Intn(len(buf)+1)]
+		for _, seed := range append(seeds, rnd.Uint32()) {
+			s := uint64(seed)
+			d32, d64, d128 := v1.New32WithSeed(seed), v1.New64WithSeed(seed), v1.New128WithSeed(seed)
+			h32, h64, h128 := murmur3.SeedNew32(seed), murmur3.SeedNew64(s), murmur3.SeedNew128(s, s)
+			s32, s64, s128 := stackmurmur3.New32WithSeed(seed), stackmurmur3.New64WithSeed(s), stackmurmur3.New128WithSeed(s, s)
+			for rest := data; ; {
+				n := rnd.Intn(40)
+				if n > len(rest) {
+					n = len(rest)
+				}
+				p := rest[:n]
+				d32, d64, d128 = d32.Write(p), d64.Write(p), d128.Write(p)
+				h32.Write(p)
+				h64.Write(p)
+				h128.Write(p)
+				s32.Write(p)
+				s64.Write(p)
+				s128.Write(p)
+				if rest = rest[n:]; len(rest) == 0 {
+					break
+				}
+			}
+
+			assert.Equal(t, h32.Sum32(), d32.Sum32())
+			assert.Equal(t, s32.Sum32(), d32.Sum32())
+			assert.Equal(t, h32.Sum(nil), d32.Sum(nil))
+			assert.Equal(t, h32.(murmur3.ByteOrderSummer).SumLE(nil), d32.SumLE(nil))
+
+			assert.Equal(t, h64.Sum64(), d64.Sum64())
+			assert.Equal(t, s64.Sum64(), d64.Sum64())
+			assert.Equal(t, h64.Sum(nil), d64.Sum(nil))
+			assert.Equal(t, h64.(murmur3.ByteOrderSummer).SumLE(nil), d64.SumLE(nil))
+
+			w1, w2 := h128.Sum128()
+			g1, g2 := s128.Sum128()
+			v1h1, v1h2 := d128.Sum128()
+			require.Equal(t, [2]uint64{w1, w2}, [2]uint64{v1h1, v1h2}, "seed %x, %d bytes", seed, len(data))
+			assert.Equal(t, [2]uint64{g1, g2}, [2]uint64{v1h1, v1h2})
+			assert.Equal(t, h128.Sum(nil), d128.Sum(nil))
+			assert.Equal(t, h128.(murmur3.ByteOrderSummer).SumLE(nil), d128.SumLE(nil))
+			assert.Equal(t, h128.(murmur3.Uint128Summer).SumUint128(), murmur3.Uint128(d128.SumUint128()))
+		}
+	}
+}
+
+func TestStream128(t *testing.T) {
+	key := testdata.RandBytes(45)
+	for _, seed := range seeds {
+		s := uint64(seed)
+		w1, w2 := murmur3.HashKey128(murmur3.SeedNew128(s, s), key)
+		h1, h2 := murmur3.HashKey128(v1.NewStream128WithSeed(seed), key)
+		assert.Equal(t, [2]uint64{w1, w2}, [2]uint64{h1, h2})
+		h1, h2 = v1.Seed128(seed).Sum128(key)
+		assert.Equal(t, [2]uint64{w1, w2}, [2]uint64{h1, h2})
+	}
+}
+
+func TestMix32(t *testing.T) {
+	f := func(h, k, length uint32) bool {
+		return v1.Mix32(h, k) == murmur3.Mix32(h, k) &&
+			v1.MixLast32(h, k) == murmur3.MixLast32(h, k) &&
+			v1.Finalize32(h, length) == murmur3.Finalize32(h, length)
+	}
+	require.NoError(t, quick.Check(f, nil))
+}
+
+func TestUint128(t *testing.T) {
+	for _, u := range []v1.Uint128{{}, {Hi: 1}, {Lo: 1}, {Hi: 0x0123456789abcdef, Lo: 0xfedcba9876543210}} {
+		w := murmur3.Uint128(u)
+		assert.Equal(t, w.Hi, u.Hi)
+		assert.Equal(t, w.Lo, u.Lo)
+		assert.Equal(t, w.IsZero(), u.IsZero())
+		assert.Equal(t, w.String(), u.String())
+		for _, v := range []v1.Uint128{{}, {Hi: 1}, {Lo: 2}} {
+			assert.Equal(t, w.Less(murmur3.Uint128(v)), u.Less(v), "%v < %v", u, v)
+		}
+	}
+}
@@ -0,0 +1,5 @@
+// Package crosscheck holds the tests that compare the v1 and v2 modules,
+// which do not depend on each other, and tools that cover both. The module
+// builds against the working tree of both through replace directives and is
+// not published.
+package crosscheck
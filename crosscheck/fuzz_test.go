@@ -0,0 +1,80 @@
+package crosscheck
+
+import (
+	"testing"
+
+	v1 "github.com/m3db/stackmurmur3"
+	murmur3 "github.com/m3db/stackmurmur3/v2"
+	"github.com/m3db/stackmurmur3/v2/testdata"
+)
+
+// writeChunks feeds data to write in chunks whose sizes are taken from sizes,
+// one byte per chunk, then writes whatever remains in one final chunk. It is
+// the helper of the v2 fuzz targets, which this module cannot import.
+func writeChunks(data, sizes []byte, write func([]byte)) {
+	for _, s := range sizes {
+		n := int(s) % 40
+		if n > len(data) {
+			n = len(data)
+		}
+		write(data[:n])
+		data = data[n:]
+	}
+	write(data)
+}
+
+// FuzzV1 checks that the v1 sums and value digests, written in arbitrary
+// chunks, agree with the v2 sums and, where cgo is available, the C
+// reference.
+func FuzzV1(f *testing.F) {
+	buf := testdata.RandBytes(64)
+	for n := 0; n <= 33; n++ {
+		f.Add(uint32(0), buf[:n], []byte{})
+		f.Add(uint32(n), buf[:n], []byte{1})
+		f.Add(uint32(0xffffffff), buf[:n], []byte{3, 0, 5})
+	}
+	f.Add(uint32(42), buf, []byte{15, 1, 16, 0, 17})
+	f.Add(uint32(42), buf, []byte{4, 4, 4, 4, 4})
+	for _, elem := range testdata.ReferenceHashes {
+		f.Add(uint32(0), []byte(elem.S), []byte{3, 6, 12})
+	}
+	f.Fuzz(func(t *testing.T, seed uint32, data, sizes []byte) {
+		s := uint64(seed)
+		w32 := murmur3.SeedSum32(seed, data)
+		w1, w2 := murmur3.SeedSum128(s, s, data)
+		if testdata.HasCReference {
+			if c := testdata.SeedSum32(seed, data); c != w32 {
+				t.Fatalf("v2 SeedSum32 = 0x%x, C reference = 0x%x", w32, c)
+			}
+			if c1, c2 := testdata.SeedSum128(seed, data); c1 != w1 || c2 != w2 {
+				t.Fatalf("v2 SeedSum128 = 0x%x-0x%x, C reference = 0x%x-0x%x", w1, w2, c1, c2)
+			}
+		}
+
+		if got := v1.Sum32WithSeed(data, seed); got != w32 {
+			t.Fatalf("v1 Sum32WithSeed = 0x%x, want 0x%x", got, w32)
+		}
+		if got := v1.Sum64WithSeed(data, seed); got != w1 {
+			t.Fatalf("v1 Sum64WithSeed = 0x%x, want 0x%x", got, w1)
+		}
+		if h1, h2 := v1.Sum128WithSeed(data, seed); h1 != w1 || h2 != w2 {
+			t.Fatalf("v1 Sum128WithSeed = 0x%x-0x%x, want 0x%x-0x%x", h1, h2, w1, w2)
+		}
+
+		d32 := v1.New32WithSeed(seed)
+		writeChunks(data, sizes, func(p []byte) { d32 = d32.Write(p) })
+		if got := d32.Sum32(); got != w32 {
+			t.Fatalf("v1 Digest32 = 0x%x, want 0x%x", got, w32)
+		}
+		d64 := v1.New64WithSeed(seed)
+		writeChunks(data, sizes, func(p []byte) { d64 = d64.Write(p) })
+		if got := d64.Sum64(); got != w1 {
+			t.Fatalf("v1 Digest64 = 0x%x, want 0x%x", got, w1)
+		}
+		d128 := v1.New128WithSeed(seed)
+		writeChunks(data, sizes, func(p []byte) { d128 = d128.Write(p) })
+		if h1, h2 := d128.Sum128(); h1 != w1 || h2 != w2 {
+			t.Fatalf("v1 Digest128 = 0x%x-0x%x, want 0x%x-0x%x", h1, h2, w1, w2)
+		}
+	})
+}
@@ -0,0 +1,22 @@
+module github.com/m3db/stackmurmur3/crosscheck
+
+go 1.20
+
+require (
+	github.com/m3db/stackmurmur3 v0.0.0-00010101000000-000000000000
+	github.com/m3db/stackmurmur3/v2 v2.0.0-00010101000000-000000000000
+	github.com/stretchr/testify v1.6.1
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c // indirect
+)
+
+// The module is only built from this repository, against the working tree
+// of both modules, and is never published.
+replace (
+	github.com/m3db/stackmurmur3 => ../
+	github.com/m3db/stackmurmur3/v2 => ../v2
+)
@@ -1,8 +1,8 @@
 package murmur3
 
 import (
-	"fmt"
-	"unsafe"
+	"encoding/binary"
+	"math/bits"
 )
 
 const (
@@ -13,9 +13,11 @@ const (
 // Digest128 is a murmur3 128 bit digest that can be written to
 // as many consequent times as necessary without heap allocations.
 type Digest128 struct {
-	digest
-	h1 uint64 // Unfinalized running hash part 1.
-	h2 uint64 // Unfinalized running hash part 2.
+	tailBuf [16]byte
+	tailIdx int    // Length of tail stored in tailBuf.
+	clen    int    // Digested input cumulative length.
+	h1      uint64 // Unfinalized running hash part 1.
+	h2      uint64 // Unfinalized running hash part 2.
 }
 
 // New128 returns a new 128 bit digest.
@@ -26,7 +28,12 @@ func New128() Digest128 {
 // New128WithSeed returns a new 128 bit digest with a seed.
 func New128WithSeed(seed uint32) Digest128 {
 	s := uint64(seed)
-	return Digest128{digest: digest{seed: seed}, h1: s, h2: s}
+	return Digest128{h1: s, h2: s}
+}
+
+// BlockSize returns the hash's underlying block size.
+func (d Digest128) BlockSize() int {
+	return 1
 }
 
 // Size returns the number of bytes Sum will return.
@@ -46,133 +53,108 @@ func (d Digest128) Sum(b []byte) []byte {
 	)
 }
 
-func (d Digest128) bmix(p []byte) (Digest128, []byte) {
+// bmix digests all the full blocks of p and returns the tail.
+func (d *Digest128) bmix(p []byte) []byte {
 	h1, h2 := d.h1, d.h2
 
-	nblocks := len(p) / 16
-	for i := 0; i < nblocks; i++ {
-		t := (*[2]uint64)(unsafe.Pointer(&p[i*16]))
-		k1, k2 := t[0], t[1]
+	for len(p) >= 16 {
+		k1 := binary.LittleEndian.Uint64(p)
+		k2 := binary.LittleEndian.Uint64(p[8:])
+		p = p[16:]
 
 		k1 *= c1_128
-		k1 = (k1 << 31) | (k1 >> 33) // rotl64(k1, 31)
+		k1 = bits.RotateLeft64(k1, 31)
 		k1 *= c2_128
 		h1 ^= k1
 
-		h1 = (h1 << 27) | (h1 >> 37) // rotl64(h1, 27)
+		h1 = bits.RotateLeft64(h1, 27)
 		h1 += h2
 		h1 = h1*5 + 0x52dce729
 
 		k2 *= c2_128
-		k2 = (k2 << 33) | (k2 >> 31) // rotl64(k2, 33)
+		k2 = bits.RotateLeft64(k2, 33)
 		k2 *= c1_128
 		h2 ^= k2
 
-		h2 = (h2 << 31) | (h2 >> 33) // rotl64(h2, 31)
+		h2 = bits.RotateLeft64(h2, 31)
 		h2 += h1
 		h2 = h2*5 + 0x38495ab5
 	}
-	d.h1, d.h2 = h1, h2
-	return d, p[nblocks*d.Size():]
-}
-
-func (d Digest128) bmixbuf() Digest128 {
-	h1, h2 := d.h1, d.h2
-
-	if d.tail != d.Size() {
-		panic(fmt.Errorf("expected full block"))
-	}
-
-	k1, k2 := d.loadUint64(0), d.loadUint64(1)
-
-	k1 *= c1_128
-	k1 = (k1 << 31) | (k1 >> 33) // rotl64(k1, 31)
-	k1 *= c2_128
-	h1 ^= k1
-
-	h1 = (h1 << 27) | (h1 >> 37) // rotl64(h1, 27)
-	h1 += h2
-	h1 = h1*5 + 0x52dce729
-
-	k2 *= c2_128
-	k2 = (k2 << 33) | (k2 >> 31) // rotl64(k2, 33)
-	k2 *= c1_128
-	h2 ^= k2
-
-	h2 = (h2 << 31) | (h2 >> 33) // rotl64(h2, 31)
-	h2 += h1
-	h2 = h2*5 + 0x38495ab5
 
 	d.h1, d.h2 = h1, h2
-	d.tail = 0
-	return d
+	return p
 }
 
 // Sum128 returns the 128 bit hash of the digest.
 func (d Digest128) Sum128() (h1, h2 uint64) {
-	h1, h2 = d.h1, d.h2
+	return finalize128(d.h1, d.h2, d.clen, d.tailBuf[:d.tailIdx])
+}
 
+// finalize128 returns the hash of clen bytes with running hash h1, h2 and
+// unprocessed tail.
+func finalize128(h1, h2 uint64, clen int, tail []byte) (uint64, uint64) {
 	var k1, k2 uint64
-	switch d.tail & 15 {
+	switch len(tail) & 15 {
 	case 15:
-		k2 ^= uint64(d.buf[14]) << 48
+		k2 ^= uint64(tail[14]) << 48
 		fallthrough
 	case 14:
-		k2 ^= uint64(d.buf[13]) << 40
+		k2 ^= uint64(tail[13]) << 40
 		fallthrough
 	case 13:
-		k2 ^= uint64(d.buf[12]) << 32
+		k2 ^= uint64(tail[12]) << 32
 		fallthrough
 	case 12:
-		k2 ^= uint64(d.buf[11]) << 24
+		k2 ^= uint64(tail[11]) << 24
 		fallthrough
 	case 11:
-		k2 ^= uint64(d.buf[10]) << 16
+		k2 ^= uint64(tail[10]) << 16
 		fallthrough
 	case 10:
-		k2 ^= uint64(d.buf[9]) << 8
+		k2 ^= uint64(tail[9]) << 8
 		fallthrough
 	case 9:
-		k2 ^= uint64(d.buf[8]) << 0
+		k2 ^= uint64(tail[8]) << 0
 
 		k2 *= c2_128
-		k2 = (k2 << 33) | (k2 >> 31) // rotl64(k2, 33)
+		k2 = bits.RotateLeft64(k2, 33)
 		k2 *= c1_128
 		h2 ^= k2
 
 		fallthrough
 
 	case 8:
-		k1 ^= uint64(d.buf[7]) << 56
+		k1 ^= uint64(tail[7]) << 56
 		fallthrough
 	case 7:
-		k1 ^= uint64(d.buf[6]) << 48
+		k1 ^= uint64(tail[6]) << 48
 		fallthrough
 	case 6:
-		k1 ^= uint64(d.buf[5]) << 40
+		k1 ^= uint64(tail[5]) << 40
 		fallthrough
 	case 5:
-		k1 ^= uint64(d.buf[4]) << 32
+		k1 ^= uint64(tail[4]) << 32
 		fallthrough
 	case 4:
-		k1 ^= uint64(d.buf[3]) << 24
+		k1 ^= uint64(tail[3]) << 24
 		fallthrough
 	case 3:
-		k1 ^= uint64(d.buf[2]) << 16
+		k1 ^= uint64(tail[2]) << 16
 		fallthrough
 	case 2:
-		k1 ^= uint64(d.buf[1]) << 8
+		k1 ^= uint64(tail[1]) << 8
 		fallthrough
 	case 1:
-		k1 ^= uint64(d.buf[0]) << 0
+		k1 ^= uint64(tail[0]) << 0
 		k1 *= c1_128
-		k1 = (k1 << 31) | (k1 >> 33) // rotl64(k1, 31)
+		k1 = bits.RotateLeft64(k1, 31)
 		k1 *= c2_128
 		h1 ^= k1
 	}
 
-	h1 ^= uint64(d.clen)
-	h2 ^= uint64(d.clen)
+	cl := uint64(clen)
+	h1 ^= cl
+	h2 ^= cl
 
 	h1 += h2
 	h2 += h1
@@ -189,46 +171,29 @@ func (d Digest128) Sum128() (h1, h2 uint64) {
 // Write will write bytes to the digest and return a new digest
 // representing the derived digest.
 func (d Digest128) Write(p []byte) Digest128 {
-	n := len(p)
-	d.clen += n
+	d.write(p)
+	return d
+}
 
-	if d.tail > 0 {
-		// Stick back pending bytes.
-		nfree := d.Size() - d.tail // nfree ∈ [1, d.Size()-1].
-		if len(p) <= nfree {
-			// Everything can fit in buf
-			for i := 0; i < len(p); i++ {
-				d.buf[d.tail] = p[i]
-				d.tail++
-			}
-			if len(p) == int(nfree) {
-				d = d.bmixbuf()
-			}
-			return d
-		}
+func (d *Digest128) write(p []byte) {
+	d.clen += len(p)
 
-		// One full block can be formed.
-		add := p[:nfree]
-		for i := 0; i < len(add); i++ {
-			d.buf[d.tail] = add[i]
-			d.tail++
+	if d.tailIdx > 0 {
+		// Stick back pending bytes.
+		nfree := len(d.tailBuf) - d.tailIdx // nfree ∈ [1, len(d.tailBuf)-1].
+		if nfree > len(p) {
+			d.tailIdx += copy(d.tailBuf[d.tailIdx:], p)
+			return
 		}
-
-		// Process the full block
-		d = d.bmixbuf()
-
+		// Process the one full block that can be formed.
+		copy(d.tailBuf[d.tailIdx:], p[:nfree])
+		d.bmix(d.tailBuf[:])
 		p = p[nfree:]
+		d.tailIdx = 0
 	}
 
-	d, tail := d.bmix(p)
-
 	// Keep own copy of the 0 to Size()-1 pending bytes.
-	d.tail = len(tail)
-	for i := 0; i < d.tail; i++ {
-		d.buf[i] = tail[i]
-	}
-
-	return d
+	d.tailIdx = copy(d.tailBuf[:], d.bmix(p))
 }
 
 func fmix64(k uint64) uint64 {
@@ -248,5 +213,7 @@ func Sum128(data []byte) (h1 uint64, h2 uint64) {
 // Sum128WithSeed returns the MurmurHash3 sum of data given a seed
 // without any heap allocations.
 func Sum128WithSeed(data []byte, seed uint32) (h1 uint64, h2 uint64) {
-	return New128WithSeed(seed).Write(data).Sum128()
+	d := New128WithSeed(seed)
+	tail := d.bmix(data)
+	return finalize128(d.h1, d.h2, len(data), tail)
 }
@@ -3,8 +3,8 @@ package murmur3
 // http://code.google.com/p/guava-libraries/source/browse/guava/src/com/google/common/hash/Murmur3_32HashFunction.java
 
 import (
-	"fmt"
-	"unsafe"
+	"encoding/binary"
+	"math/bits"
 )
 
 const (
@@ -14,8 +14,10 @@ const (
 
 // Digest32 represents a partial evaluation of a 32 bits hash.
 type Digest32 struct {
-	digest
-	h1 uint32 // Unfinalized running hash.
+	tailBuf [4]byte
+	tailIdx int    // Length of tail stored in tailBuf.
+	clen    int    // Digested input cumulative length.
+	h1      uint32 // Unfinalized running hash.
 }
 
 // New32 returns new 32-bit hasher
@@ -23,7 +25,11 @@ func New32() Digest32 { return New32WithSeed(0) }
 
 // New32WithSeed returns new 32-bit hasher set with explicit seed value
 func New32WithSeed(seed uint32) Digest32 {
-	return Digest32{digest: digest{seed: seed}, h1: seed}
+	return Digest32{h1: seed}
+}
+
+func (d Digest32) BlockSize() int {
+	return 1
 }
 
 func (d Digest32) Size() int {
@@ -35,130 +41,56 @@ func (d Digest32) Sum(b []byte) []byte {
 	return append(b, byte(h>>24), byte(h>>16), byte(h>>8), byte(h))
 }
 
-// Digest as many blocks as possible.
-func (d Digest32) bmix(p []byte) (Digest32, []byte) {
-	h1 := d.h1
-
-	nblocks := len(p) / 4
-	for i := 0; i < nblocks; i++ {
-		k1 := *(*uint32)(unsafe.Pointer(&p[i*4]))
-
-		k1 *= c1_32
-		k1 = (k1 << 15) | (k1 >> 17) // rotl32(k1, 15)
-		k1 *= c2_32
-
-		h1 ^= k1
-		h1 = (h1 << 13) | (h1 >> 19) // rotl32(h1, 13)
-		h1 = h1*4 + h1 + 0xe6546b64
-	}
-	d.h1 = h1
-	return d, p[nblocks*d.Size():]
-}
-
-func (d Digest32) bmixbuf() Digest32 {
-	h1 := d.h1
-
-	if d.tail != d.Size() {
-		panic(fmt.Errorf("expected full block"))
-	}
-
-	k1 := d.loadUint32(0)
-
-	k1 *= c1_32
-	k1 = (k1 << 15) | (k1 >> 17) // rotl32(k1, 15)
-	k1 *= c2_32
-
-	h1 ^= k1
-	h1 = (h1 << 13) | (h1 >> 19) // rotl32(h1, 13)
-	h1 = h1*4 + h1 + 0xe6546b64
-
-	d.h1 = h1
-	d.tail = 0
-	return d
-}
-
 func (d Digest32) Sum32() (h1 uint32) {
-
 	h1 = d.h1
 
 	var k1 uint32
-	switch d.tail & 3 {
+	switch d.tailIdx & 3 {
 	case 3:
-		k1 ^= uint32(d.buf[2]) << 16
+		k1 ^= uint32(d.tailBuf[2]) << 16
 		fallthrough
 	case 2:
-		k1 ^= uint32(d.buf[1]) << 8
+		k1 ^= uint32(d.tailBuf[1]) << 8
 		fallthrough
 	case 1:
-		k1 ^= uint32(d.buf[0])
-		k1 *= c1_32
-		k1 = (k1 << 15) | (k1 >> 17) // rotl32(k1, 15)
-		k1 *= c2_32
-		h1 ^= k1
+		k1 ^= uint32(d.tailBuf[0])
+		h1 = MixLast32(h1, k1)
 	}
 
-	h1 ^= uint32(d.clen)
-
-	h1 ^= h1 >> 16
-	h1 *= 0x85ebca6b
-	h1 ^= h1 >> 13
-	h1 *= 0xc2b2ae35
-	h1 ^= h1 >> 16
-
-	return h1
+	return Finalize32(h1, uint32(d.clen))
 }
 
 // Write will write bytes to the digest and return a new digest
 // representing the derived digest.
 func (d Digest32) Write(p []byte) Digest32 {
-	n := len(p)
-	d.clen += n
+	d.clen += len(p)
 
-	if d.tail > 0 {
+	if d.tailIdx > 0 {
 		// Stick back pending bytes.
-		nfree := d.Size() - d.tail // nfree ∈ [1, d.size-1].
-		if len(p) <= int(nfree) {
-			// Everything can fit in buf
-			for i := 0; i < len(p); i++ {
-				d.buf[d.tail] = p[i]
-				d.tail++
-			}
-			if len(p) == int(nfree) {
-				d = d.bmixbuf()
-			}
+		nfree := len(d.tailBuf) - d.tailIdx // nfree ∈ [1, len(d.tailBuf)-1].
+		if nfree > len(p) {
+			d.tailIdx += copy(d.tailBuf[d.tailIdx:], p)
 			return d
 		}
-
-		// One full block can be formed.
-		add := p[:nfree]
-		for i := 0; i < len(add); i++ {
-			d.buf[d.tail] = add[i]
-			d.tail++
-		}
-
-		// Process the full block
-		d = d.bmixbuf()
-
+		// Process the one full block that can be formed.
+		copy(d.tailBuf[d.tailIdx:], p[:nfree])
+		d.h1 = Mix32(d.h1, binary.LittleEndian.Uint32(d.tailBuf[:]))
 		p = p[nfree:]
+		d.tailIdx = 0
 	}
 
-	d, tail := d.bmix(p)
-
-	// Keep own copy of the 0 to Size()-1 pending bytes.
-	d.tail = len(tail)
-	for i := 0; i < d.tail; i++ {
-		d.buf[i] = tail[i]
+	h1 := d.h1
+	n := len(p) &^ 3
+	for i := 0; i < n; i += 4 {
+		h1 = Mix32(h1, binary.LittleEndian.Uint32(p[i:]))
 	}
+	d.h1 = h1
 
+	// Keep own copy of the 0 to Size()-1 pending bytes.
+	d.tailIdx = copy(d.tailBuf[:], p[n:])
 	return d
 }
 
-/*
-func rotl32(x uint32, r byte) uint32 {
-	return (x << r) | (x >> (32 - r))
-}
-*/
-
 // Sum32 returns the MurmurHash3 sum of data. It is equivalent to the
 // following sequence (without the extra burden and the extra allocation):
 //     hasher := New32()
@@ -174,25 +106,14 @@ func Sum32(data []byte) uint32 { return Sum32WithSeed(data, 0) }
 func Sum32WithSeed(data []byte, seed uint32) uint32 {
 	h1 := seed
 
-	nblocks := len(data) / 4
-	var p uintptr
-	if len(data) > 0 {
-		p = uintptr(unsafe.Pointer(&data[0]))
-	}
-	p1 := p + uintptr(4*nblocks)
-	for ; p < p1; p += 4 {
-		k1 := *(*uint32)(unsafe.Pointer(p))
-
-		k1 *= c1_32
-		k1 = (k1 << 15) | (k1 >> 17) // rotl32(k1, 15)
-		k1 *= c2_32
-
-		h1 ^= k1
-		h1 = (h1 << 13) | (h1 >> 19) // rotl32(h1, 13)
-		h1 = h1*4 + h1 + 0xe6546b64
+	// Explicit little endian loads keep this checkptr clean, alignment safe
+	// and portable; the compiler turns them into single loads where it can.
+	n := len(data) &^ 3
+	for i := 0; i < n; i += 4 {
+		h1 = Mix32(h1, binary.LittleEndian.Uint32(data[i:]))
 	}
 
-	tail := data[nblocks*4:]
+	tail := data[n:]
 
 	var k1 uint32
 	switch len(tail) & 3 {
@@ -204,19 +125,39 @@ func Sum32WithSeed(data []byte, seed uint32) uint32 {
 		fallthrough
 	case 1:
 		k1 ^= uint32(tail[0])
-		k1 *= c1_32
-		k1 = (k1 << 15) | (k1 >> 17) // rotl32(k1, 15)
-		k1 *= c2_32
-		h1 ^= k1
+		h1 = MixLast32(h1, k1)
 	}
 
-	h1 ^= uint32(len(data))
+	return Finalize32(h1, uint32(len(data)))
+}
+
+// The x86_32 steps below are exported for hashes built from them. Sum32 of
+// data is Mix32 of each little endian block, MixLast32 of the zero padded
+// tail if any, and Finalize32 with the length of data.
 
-	h1 ^= h1 >> 16
-	h1 *= 0x85ebca6b
-	h1 ^= h1 >> 13
-	h1 *= 0xc2b2ae35
-	h1 ^= h1 >> 16
+// Mix32 mixes the block k into the running hash h.
+func Mix32(h, k uint32) uint32 {
+	h = MixLast32(h, k)
+	h = bits.RotateLeft32(h, 13)
+	return h*5 + 0xe6546b64
+}
+
+// MixLast32 mixes the last block k into the running hash h. Unlike Mix32, it
+// leaves h otherwise unchanged.
+func MixLast32(h, k uint32) uint32 {
+	k *= c1_32
+	k = bits.RotateLeft32(k, 15)
+	k *= c2_32
+	return h ^ k
+}
 
-	return h1
+// Finalize32 returns the hash of length bytes, whose running hash is h.
+func Finalize32(h, length uint32) uint32 {
+	h ^= length
+	h ^= h >> 16
+	h *= 0x85ebca6b
+	h ^= h >> 13
+	h *= 0xc2b2ae35
+	h ^= h >> 16
+	return h
 }
@@ -11,6 +11,10 @@ func New64WithSeed(seed uint32) Digest64 {
 	return Digest64(New128WithSeed(seed))
 }
 
+func (d Digest64) BlockSize() int {
+	return 1
+}
+
 func (d Digest64) Size() int {
 	return 8
 }
@@ -44,5 +48,6 @@ func Sum64(data []byte) uint64 { return Sum64WithSeed(data, 0) }
 //     hasher.Write(data)
 //     return hasher.Sum64()
 func Sum64WithSeed(data []byte, seed uint32) uint64 {
-	return New64WithSeed(seed).Write(data).Sum64()
+	h1, _ := Sum128WithSeed(data, seed)
+	return h1
 }
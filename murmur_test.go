@@ -5,6 +5,7 @@ import (
 	"math/rand"
 	"runtime"
 	"testing"
+	"unsafe"
 
 	murmur3origin "github.com/spaolacci/murmur3"
 	"github.com/stretchr/testify/assert"
@@ -111,6 +112,114 @@ func TestIncremental(t *testing.T) {
 	}
 }
 
+// TestReferenceHashes checks the golden values. They are fixed little endian
+// results, so running this under emulated big endian architectures (see
+// scripts/test-qemu.sh) catches native load order bugs.
+func TestReferenceHashes(t *testing.T) {
+	for _, elem := range data {
+		// Offset the input so that block loads are unaligned as well.
+		buf := append([]byte{0}, elem.s...)[1:]
+
+		if v := Sum32WithSeed(buf, elem.seed); v != elem.h32 {
+			t.Errorf("[Sum32] key: '%s', seed: '%d': 0x%x (want 0x%x)", elem.s, elem.seed, v, elem.h32)
+		}
+		if v := New32WithSeed(elem.seed).Write(buf).Sum32(); v != elem.h32 {
+			t.Errorf("[Digest32] key: '%s', seed: '%d': 0x%x (want 0x%x)", elem.s, elem.seed, v, elem.h32)
+		}
+		if v := Sum64WithSeed(buf, elem.seed); v != elem.h64_1 {
+			t.Errorf("[Sum64] key: '%s', seed: '%d': 0x%x (want 0x%x)", elem.s, elem.seed, v, elem.h64_1)
+		}
+		if v1, v2 := Sum128WithSeed(buf, elem.seed); v1 != elem.h64_1 || v2 != elem.h64_2 {
+			t.Errorf("[Sum128] key: '%s', seed: '%d': 0x%x-0x%x (want 0x%x-0x%x)", elem.s, elem.seed, v1, v2, elem.h64_1, elem.h64_2)
+		}
+		if v1, v2 := New128WithSeed(elem.seed).Write(buf).Sum128(); v1 != elem.h64_1 || v2 != elem.h64_2 {
+			t.Errorf("[Digest128] key: '%s', seed: '%d': 0x%x-0x%x (want 0x%x-0x%x)", elem.s, elem.seed, v1, v2, elem.h64_1, elem.h64_2)
+		}
+	}
+}
+
+// isLittleEndian reports whether the native byte order is little endian.
+// The origin package loads blocks in native order, so it only matches the
+// portable sums there.
+var isLittleEndian = func() bool {
+	i := uint16(1)
+	return (*(*[2]byte)(unsafe.Pointer(&i)))[0] == 1
+}()
+
+// randBytes returns n bytes from a fixed source.
+func randBytes(n int) []byte {
+	b := make([]byte, n)
+	rand.New(rand.NewSource(0)).Read(b)
+	return b
+}
+
+// verificationValue computes SMHasher's verification value of a hash of n
+// bytes, which sum appends little endian: keys {0, 1, ..., i-1} are hashed
+// with seed 256-i, and the concatenation of those hashes with seed 0.
+func verificationValue(sum func(b, key []byte, seed uint32) []byte) uint32 {
+	var key [256]byte
+	var hashes []byte
+	for i := 0; i < 256; i++ {
+		key[i] = byte(i)
+		hashes = sum(hashes, key[:i], uint32(256-i))
+	}
+	final := sum(nil, hashes, 0)
+	return uint32(final[0]) | uint32(final[1])<<8 | uint32(final[2])<<16 | uint32(final[3])<<24
+}
+
+// TestVerification checks the SMHasher verification values of the x86_32
+// and x64_128 hashes, as the v2 quality suite does for the v2 sums.
+func TestVerification(t *testing.T) {
+	assert.Equal(t, uint32(0xB0F57EE3), verificationValue(AppendCanonical32))
+	assert.Equal(t, uint32(0x6384BA69), verificationValue(AppendCanonical128))
+	assert.Equal(t, uint32(0x6384BA69), verificationValue(func(b, key []byte, seed uint32) []byte {
+		return New128WithSeed(seed).Write(key).SumLE(b)
+	}))
+}
+
+// TestMix32 checks that the exported x86_32 steps compose to Sum32WithSeed.
+func TestMix32(t *testing.T) {
+	buf := randBytes(40)
+	for n := 0; n <= len(buf); n++ {
+		data, seed := buf[:n], uint32(n)*0x9e3779b1
+		h := seed
+		i := 0
+		for ; i+4 <= n; i += 4 {
+			h = Mix32(h, uint32(data[i])|uint32(data[i+1])<<8|uint32(data[i+2])<<16|uint32(data[i+3])<<24)
+		}
+		if i < n {
+			var k uint32
+			for j := n - 1; j >= i; j-- {
+				k = k<<8 | uint32(data[j])
+			}
+			h = MixLast32(h, k)
+		}
+		assert.Equal(t, Sum32WithSeed(data, seed), Finalize32(h, uint32(n)), n)
+	}
+}
+
+// TestWriteReturnsDerivedDigest checks that Write leaves its receiver
+// untouched, so a digest can be shared as a prefix between derivations.
+func TestWriteReturnsDerivedDigest(t *testing.T) {
+	prefix := New128().Write([]byte("hello, "))
+	a := prefix.Write([]byte("world"))
+	b := prefix.Write([]byte("there"))
+
+	h1, h2 := prefix.Sum128()
+	w1, w2 := Sum128([]byte("hello, "))
+	assert.Equal(t, [2]uint64{w1, w2}, [2]uint64{h1, h2})
+	h1, h2 = a.Sum128()
+	w1, w2 = Sum128([]byte("hello, world"))
+	assert.Equal(t, [2]uint64{w1, w2}, [2]uint64{h1, h2})
+	h1, h2 = b.Sum128()
+	w1, w2 = Sum128([]byte("hello, there"))
+	assert.Equal(t, [2]uint64{w1, w2}, [2]uint64{h1, h2})
+
+	p32 := New32().Write([]byte("abcde"))
+	_ = p32.Write([]byte("fgh"))
+	assert.Equal(t, Sum32([]byte("abcde")), p32.Sum32())
+}
+
 //---
 
 func originIncrementalBench128(b *testing.B, length int) {
@@ -458,3 +567,60 @@ func TestDigest128ZeroAllocWrite(t *testing.T) {
 }
 
 //---
+
+func TestSumUint128(t *testing.T) {
+	for _, elem := range data {
+		want := Uint128{Hi: elem.h64_1, Lo: elem.h64_2}
+		assert.Equal(t, want, SumUint128WithSeed([]byte(elem.s), elem.seed))
+		assert.Equal(t, want, New128WithSeed(elem.seed).Write([]byte(elem.s)).SumUint128())
+		assert.Equal(t, fmt.Sprintf("%x", New128WithSeed(elem.seed).Write([]byte(elem.s)).Sum(nil)), want.String())
+		if elem.seed == 0 {
+			assert.Equal(t, want, SumUint128([]byte(elem.s)))
+		}
+	}
+
+	assert.True(t, Uint128{}.IsZero())
+	assert.False(t, Uint128{Lo: 1}.IsZero())
+	assert.True(t, Uint128{Hi: 1}.Less(Uint128{Hi: 2}))
+	assert.True(t, Uint128{Hi: 1, Lo: 5}.Less(Uint128{Hi: 1, Lo: 6}))
+	assert.False(t, Uint128{Hi: 2}.Less(Uint128{Hi: 1, Lo: 9}))
+	assert.False(t, Uint128{Hi: 1}.Less(Uint128{Hi: 1}))
+}
+
+// reverseWords reverses the byte order of each word of size n in b.
+func reverseWords(b []byte, n int) []byte {
+	r := make([]byte, len(b))
+	for i := 0; i < len(b); i += n {
+		for j := 0; j < n; j++ {
+			r[i+j] = b[i+n-1-j]
+		}
+	}
+	return r
+}
+
+func TestByteOrder(t *testing.T) {
+	buf := randBytes(100)
+	for n := 0; n <= len(buf); n++ {
+		data := buf[:n]
+		seed := uint32(n)
+
+		d32 := New32WithSeed(seed).Write(data)
+		d64 := New64WithSeed(seed).Write(data)
+		d128 := New128WithSeed(seed).Write(data)
+
+		assert.Equal(t, AppendCanonical32(nil, data, seed), d32.SumLE(nil))
+		assert.Equal(t, AppendCanonical64(nil, data, seed), d64.SumLE(nil))
+		assert.Equal(t, AppendCanonical128(nil, data, seed), d128.SumLE(nil))
+		assert.Equal(t, reverseWords(d32.Sum(nil), 4), d32.SumLE(nil))
+		assert.Equal(t, reverseWords(d64.Sum(nil), 8), d64.SumLE(nil))
+		assert.Equal(t, reverseWords(d128.Sum(nil), 8), d128.SumLE(nil))
+		assert.Equal(t, d32.Sum(nil), d32.SumBE(nil))
+		assert.Equal(t, d64.Sum(nil), d64.SumBE(nil))
+		assert.Equal(t, d128.Sum(nil), d128.SumBE(nil))
+	}
+
+	// The C reference writes "hello" as these bytes.
+	assert.Equal(t, []byte{0x47, 0xfa, 0x8b, 0x24}, AppendCanonical32(nil, []byte("hello"), 0))
+	want := []byte{0x02, 0x9b, 0xbd, 0x41, 0xb3, 0xa7, 0xd8, 0xcb, 0x19, 0x1d, 0xae, 0x48, 0x6a, 0x90, 0x1e, 0x5b}
+	assert.Equal(t, want, AppendCanonical128(nil, []byte("hello"), 0))
+}
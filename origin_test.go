@@ -0,0 +1,59 @@
+//go:build !race
+
+package murmur3
+
+// The origin package loads blocks through unsafe pointer arithmetic that
+// fails the pointer checks -race turns on, so the comparisons against it are
+// left out of race builds such as scripts/test-checkptr.sh.
+
+import (
+	"math/rand"
+	"testing"
+
+	murmur3origin "github.com/spaolacci/murmur3"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestOriginEquivalence checks the digests and sums against the origin
+// package, an independent implementation, over random lengths, seeds and
+// write chunkings.
+func TestOriginEquivalence(t *testing.T) {
+	if !isLittleEndian {
+		t.Skip("the origin package loads blocks in native byte order")
+	}
+	rnd := rand.New(rand.NewSource(1))
+	buf := randBytes(300)
+	for i := 0; i < 1000; i++ {
+		p := buf[:rnd.Intn(len(buf)+1)]
+		seed := rnd.Uint32()
+
+		w32 := murmur3origin.New32WithSeed(seed)
+		w128 := murmur3origin.New128WithSeed(seed)
+		d32, d64, d128 := New32WithSeed(seed), New64WithSeed(seed), New128WithSeed(seed)
+		for rest := p; ; {
+			n := rnd.Intn(40)
+			if n > len(rest) {
+				n = len(rest)
+			}
+			w32.Write(rest[:n])
+			w128.Write(rest[:n])
+			d32, d64, d128 = d32.Write(rest[:n]), d64.Write(rest[:n]), d128.Write(rest[:n])
+			if rest = rest[n:]; len(rest) == 0 {
+				break
+			}
+		}
+
+		h1, h2 := w128.Sum128()
+		assert.Equal(t, murmur3origin.Sum32WithSeed(p, seed), Sum32WithSeed(p, seed))
+		assert.Equal(t, w32.Sum32(), d32.Sum32())
+		assert.Equal(t, w32.Sum(nil), d32.Sum(nil))
+		assert.Equal(t, h1, Sum64WithSeed(p, seed))
+		assert.Equal(t, h1, d64.Sum64())
+		assert.Equal(t, w128.Sum(nil)[:8], d64.Sum(nil))
+		v1, v2 := Sum128WithSeed(p, seed)
+		assert.Equal(t, [2]uint64{h1, h2}, [2]uint64{v1, v2})
+		v1, v2 = d128.Sum128()
+		assert.Equal(t, [2]uint64{h1, h2}, [2]uint64{v1, v2})
+		assert.Equal(t, w128.Sum(nil), d128.Sum(nil))
+	}
+}
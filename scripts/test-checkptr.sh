@@ -0,0 +1,17 @@
+#!/bin/sh
+# Runs the test suites of both modules, and of the crosscheck module that
+# compares them, with the race detector and pointer checks (-d=checkptr)
+# enabled for every package, so invalid unsafe.Pointer conversions and
+# arithmetic fail loudly instead of corrupting memory. The v1
+# comparisons against github.com/spaolacci/murmur3, which is not checkptr
+# clean, are built with !race and so left out.
+#
+# Usage: scripts/test-checkptr.sh [go test flags...]
+set -eu
+
+cd "$(dirname "$0")/.."
+
+for dir in . v2 crosscheck; do
+	echo "--- $dir"
+	(cd "$dir" && go test -race -gcflags=all=-d=checkptr "$@" ./...)
+done
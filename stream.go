@@ -0,0 +1,49 @@
+package murmur3
+
+// Stream128 adapts the value returning Digest128 to the method set of the v2
+// murmur3.Streaming128 interface, so that it can be used with the generic
+// helpers there. Hand the helpers the same Stream128 for many keys.
+type Stream128 struct {
+	d    Digest128
+	seed uint32
+}
+
+// NewStream128WithSeed returns a Stream128 set with explicit seed value.
+func NewStream128WithSeed(seed uint32) *Stream128 {
+	return &Stream128{d: New128WithSeed(seed), seed: seed}
+}
+
+// NewStream128 returns a new Stream128.
+func NewStream128() *Stream128 {
+	return NewStream128WithSeed(0)
+}
+
+// Digest returns the current digest.
+func (s *Stream128) Digest() Digest128 {
+	return s.d
+}
+
+// Write adds p to the digest. It never fails.
+func (s *Stream128) Write(p []byte) (int, error) {
+	s.d = s.d.Write(p)
+	return len(p), nil
+}
+
+// Sum128 returns the 128 bit hash of the digest.
+func (s *Stream128) Sum128() (h1, h2 uint64) {
+	return s.d.Sum128()
+}
+
+// Reset returns the digest to its seeded state.
+func (s *Stream128) Reset() {
+	s.d = New128WithSeed(s.seed)
+}
+
+// Seed128 hashes with Sum128WithSeed. It has the method set of the v2
+// murmur3.OneShot128 interface.
+type Seed128 uint32
+
+// Sum128 returns Sum128WithSeed(data, uint32(s)).
+func (s Seed128) Sum128(data []byte) (h1, h2 uint64) {
+	return Sum128WithSeed(data, uint32(s))
+}
@@ -0,0 +1,84 @@
+package murmur3
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// streaming128 and oneShot128 are the v2 murmur3.Streaming128 and
+// murmur3.OneShot128 interfaces, which the adapters satisfy without this
+// module depending on v2.
+type streaming128 interface {
+	io.Writer
+	Sum128() (h1, h2 uint64)
+	Reset()
+}
+
+type oneShot128 interface {
+	Sum128(data []byte) (h1, h2 uint64)
+}
+
+var (
+	_ streaming128 = new(Stream128)
+	_ oneShot128   = Seed128(0)
+)
+
+// hashKey128 is the v2 murmur3.HashKey128 helper.
+func hashKey128(s streaming128, key []byte) (h1, h2 uint64) {
+	s.Reset()
+	s.Write(key)
+	return s.Sum128()
+}
+
+func TestStream128(t *testing.T) {
+	s := NewStream128WithSeed(7)
+	s.Write([]byte("hello, "))
+	s.Write([]byte("world"))
+	h1, h2 := s.Sum128()
+	w1, w2 := Sum128WithSeed([]byte("hello, world"), 7)
+	assert.Equal(t, [2]uint64{w1, w2}, [2]uint64{h1, h2})
+	assert.Equal(t, New128WithSeed(7).Write([]byte("hello, world")), s.Digest())
+
+	h1, h2 = hashKey128(s, []byte("hello, world"))
+	assert.Equal(t, [2]uint64{w1, w2}, [2]uint64{h1, h2})
+	s.Reset()
+	assert.Equal(t, New128WithSeed(7), s.Digest())
+
+	var o oneShot128 = Seed128(7)
+	h1, h2 = o.Sum128([]byte("hello, world"))
+	assert.Equal(t, [2]uint64{w1, w2}, [2]uint64{h1, h2})
+}
+
+func TestStream128ZeroAlloc(t *testing.T) {
+	var s streaming128 = NewStream128()
+	var o oneShot128 = Seed128(1)
+	key := make([]byte, 40)
+	var h1, h2 uint64
+	allocs := testing.AllocsPerRun(100, func() {
+		h1, h2 = hashKey128(s, key)
+		h1, h2 = o.Sum128(key)
+	})
+	assert.Zero(t, allocs)
+	_, _ = h1, h2
+}
+
+func BenchmarkStream128HashKey(b *testing.B) {
+	key := make([]byte, 32)
+	var h1, h2 uint64
+	b.Run("direct", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			h1, h2 = New128().Write(key).Sum128()
+		}
+	})
+	b.Run("Stream128", func(b *testing.B) {
+		s := NewStream128()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			h1, h2 = hashKey128(s, key)
+		}
+	})
+	_, _ = h1, h2
+}
@@ -0,0 +1,46 @@
+package murmur3
+
+import "encoding/hex"
+
+// Uint128 is a 128 bit sum as a single comparable value, usable as a map key.
+// Hi holds h1 and Lo holds h2. It has the layout of the v2 murmur3.Uint128,
+// which has the other encodings and the arithmetic, and converts to it.
+type Uint128 struct {
+	Hi, Lo uint64
+}
+
+// SumUint128 returns the 128 bit hash of the digest as a Uint128.
+func (d Digest128) SumUint128() Uint128 {
+	h1, h2 := d.Sum128()
+	return Uint128{h1, h2}
+}
+
+// SumUint128 returns Sum128(data) as a Uint128.
+func SumUint128(data []byte) Uint128 {
+	return SumUint128WithSeed(data, 0)
+}
+
+// SumUint128WithSeed returns Sum128WithSeed(data, seed) as a Uint128.
+func SumUint128WithSeed(data []byte, seed uint32) Uint128 {
+	h1, h2 := Sum128WithSeed(data, seed)
+	return Uint128{h1, h2}
+}
+
+// IsZero reports whether u is zero.
+func (u Uint128) IsZero() bool { return u == Uint128{} }
+
+// Less reports whether u < v.
+func (u Uint128) Less(v Uint128) bool {
+	return u.Hi < v.Hi || (u.Hi == v.Hi && u.Lo < v.Lo)
+}
+
+// String returns u as 32 lowercase hex digits, the hex encoding of the
+// Digest128 Sum.
+func (u Uint128) String() string {
+	var b [16]byte
+	for i := 0; i < 8; i++ {
+		b[i] = byte(u.Hi >> (56 - 8*i))
+		b[8+i] = byte(u.Lo >> (56 - 8*i))
+	}
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,91 @@
+package murmur3
+
+import "encoding/binary"
+
+// Make sure interfaces are correctly implemented.
+var (
+	_ ByteOrderSummer = new(digest32)
+	_ ByteOrderSummer = new(digest64)
+	_ ByteOrderSummer = new(digest128)
+)
+
+// ByteOrderSummer is implemented by every digest in this package, and makes
+// the byte order of the appended sum explicit.
+//
+// SumBE appends the sum high-order bytes first, as Sum does; a 128 bit sum is
+// h1 followed by h2. SumLE appends each word low-order bytes first, which is
+// what the C reference implementation writes to its output buffer on every
+// common platform, and what Python's mmh3.hash_bytes returns.
+type ByteOrderSummer interface {
+	SumBE(b []byte) []byte
+	SumLE(b []byte) []byte
+}
+
+// SumBE appends the big endian sum to b. It is the same as Sum.
+func (d *digest32) SumBE(b []byte) []byte { return d.Sum(b) }
+
+// SumLE appends the little endian sum to b.
+func (d *digest32) SumLE(b []byte) []byte {
+	return binary.LittleEndian.AppendUint32(b, d.Sum32())
+}
+
+// SumBE appends the big endian sum to b. It is the same as Sum.
+func (d *digest64) SumBE(b []byte) []byte { return d.Sum(b) }
+
+// SumLE appends the little endian sum to b.
+func (d *digest64) SumLE(b []byte) []byte {
+	return binary.LittleEndian.AppendUint64(b, d.Sum64())
+}
+
+// SumBE appends the big endian sum to b. It is the same as Sum.
+func (d *digest128) SumBE(b []byte) []byte { return d.Sum(b) }
+
+// SumLE appends h1 then h2 to b, each little endian.
+func (d *digest128) SumLE(b []byte) []byte {
+	h1, h2 := d.Sum128()
+	return appendLE128(b, h1, h2)
+}
+
+// appendLE128 appends h1 then h2 to b, each little endian; these are the
+// bytes the C reference writes.
+func appendLE128(b []byte, h1, h2 uint64) []byte {
+	b = binary.LittleEndian.AppendUint64(b, h1)
+	return binary.LittleEndian.AppendUint64(b, h2)
+}
+
+// AppendBE appends the 16 byte big endian encoding of the number
+// Hi<<64 | Lo to b: Hi then Lo, each big endian. For a sum this is h1 then h2,
+// the bytes of Sum and SumBE.
+func (u Uint128) AppendBE(b []byte) []byte {
+	b = binary.BigEndian.AppendUint64(b, u.Hi)
+	return binary.BigEndian.AppendUint64(b, u.Lo)
+}
+
+// AppendLE appends the 16 byte little endian encoding of the number
+// Hi<<64 | Lo to b: Lo then Hi, each little endian, the reverse of AppendBE.
+// For a sum this is h2 then h1, so it is not the byte order of SumLE and the
+// C reference, which write h1 first; use SumLE or AppendCanonical128 for
+// those.
+func (u Uint128) AppendLE(b []byte) []byte {
+	b = binary.LittleEndian.AppendUint64(b, u.Lo)
+	return binary.LittleEndian.AppendUint64(b, u.Hi)
+}
+
+// AppendCanonical32 appends SeedSum32(seed, data) to dst in the byte order of
+// the C reference MurmurHash3_x86_32 output.
+func AppendCanonical32(dst []byte, seed uint32, data []byte) []byte {
+	return binary.LittleEndian.AppendUint32(dst, SeedSum32(seed, data))
+}
+
+// AppendCanonical64 appends SeedSum64(seed, data) to dst in the byte order of
+// the first half of the C reference MurmurHash3_x64_128 output.
+func AppendCanonical64(dst []byte, seed uint64, data []byte) []byte {
+	return binary.LittleEndian.AppendUint64(dst, SeedSum64(seed, data))
+}
+
+// AppendCanonical128 appends SeedSum128(seed1, seed2, data) to dst in the
+// byte order of the C reference MurmurHash3_x64_128 output.
+func AppendCanonical128(dst []byte, seed1, seed2 uint64, data []byte) []byte {
+	h1, h2 := SeedSum128(seed1, seed2, data)
+	return appendLE128(dst, h1, h2)
+}
@@ -0,0 +1,57 @@
+package murmur3
+
+import (
+	"encoding/binary"
+	"hash"
+	"testing"
+
+	"github.com/m3db/stackmurmur3/v2/testdata"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestByteOrder(t *testing.T) {
+	buf := testdata.RandBytes(100)
+	for n := 0; n <= len(buf); n++ {
+		data := buf[:n]
+		seed := uint32(n) * 0x9e3779b1
+		s := uint64(seed)
+
+		h32 := SeedSum32(seed, data)
+		h1, h2 := SeedSum128(s, s, data)
+		want32 := binary.LittleEndian.AppendUint32(nil, h32)
+		want64 := binary.LittleEndian.AppendUint64(nil, h1)
+		want128 := binary.LittleEndian.AppendUint64(append([]byte(nil), want64...), h2)
+		if testdata.HasCReference {
+			c32 := testdata.SeedSum32Bytes(seed, data)
+			c128 := testdata.SeedSum128Bytes(seed, data)
+			assert.Equal(t, c32[:], want32)
+			assert.Equal(t, c128[:], want128)
+		}
+
+		assert.Equal(t, want32, AppendCanonical32(nil, seed, data))
+		assert.Equal(t, want64, AppendCanonical64(nil, s, data))
+		assert.Equal(t, want128, AppendCanonical128(nil, s, s, data))
+		assert.Equal(t, append([]byte("x"), want128...), AppendCanonical128([]byte("x"), s, s, data))
+
+		d32 := SeedNew32(seed)
+		d64 := SeedNew64(s)
+		d128 := SeedNew128(s, s)
+		for _, d := range []hash.Hash{d32, d64, d128} {
+			d.Write(data)
+		}
+		assert.Equal(t, want32, d32.(ByteOrderSummer).SumLE(nil))
+		assert.Equal(t, want64, d64.(ByteOrderSummer).SumLE(nil))
+		assert.Equal(t, want128, d128.(ByteOrderSummer).SumLE(nil))
+		assert.Equal(t, d32.Sum(nil), d32.(ByteOrderSummer).SumBE(nil))
+		assert.Equal(t, d64.Sum(nil), d64.(ByteOrderSummer).SumBE(nil))
+		assert.Equal(t, d128.Sum(nil), d128.(ByteOrderSummer).SumBE(nil))
+
+		u := Uint128{h1, h2}
+		b := u.Bytes()
+		assert.Equal(t, b[:], u.AppendBE(nil))
+		le := u.AppendLE(nil)
+		for i := range b {
+			assert.Equal(t, b[i], le[len(le)-1-i])
+		}
+	}
+}
@@ -0,0 +1,92 @@
+// Package compat presents murmur3 sums exactly as other widely used
+// implementations do, so that IDs computed in Go can be checked against them
+// without reasoning about signs, halves and byte orders.
+//
+// The MMH3 functions match the Python mmh3 module, whose str keys are hashed
+// as their UTF-8 bytes. Only its default x64arch=True variants are provided;
+// the x86_128 sums it computes otherwise are a different algorithm. The
+// MurmurHash3 functions match the ClickHouse SQL functions of the same name
+// for a single String argument.
+package compat
+
+import (
+	"encoding/binary"
+	"math/big"
+
+	murmur3 "github.com/m3db/stackmurmur3/v2"
+)
+
+// MMH3Hash returns mmh3.hash(key, seed), the x86_32 sum as a signed integer.
+func MMH3Hash(key []byte, seed uint32) int32 {
+	return int32(murmur3.SeedSum32(seed, key))
+}
+
+// MMH3HashUnsigned returns mmh3.hash(key, seed, signed=False).
+func MMH3HashUnsigned(key []byte, seed uint32) uint32 {
+	return murmur3.SeedSum32(seed, key)
+}
+
+// MMH3Hash64 returns mmh3.hash64(key, seed): the two halves of the x64_128
+// sum, h1 first, as signed integers.
+func MMH3Hash64(key []byte, seed uint32) (int64, int64) {
+	h1, h2 := murmur3.SeedSum128(uint64(seed), uint64(seed), key)
+	return int64(h1), int64(h2)
+}
+
+// MMH3Hash64Unsigned returns mmh3.hash64(key, seed, signed=False).
+func MMH3Hash64Unsigned(key []byte, seed uint32) (uint64, uint64) {
+	return murmur3.SeedSum128(uint64(seed), uint64(seed), key)
+}
+
+// MMH3Hash128 returns mmh3.hash128(key, seed): the 16 bytes of the x64_128
+// sum read as a little endian integer, so h2 is the high half and AppendLE
+// gives back MMH3HashBytes.
+func MMH3Hash128(key []byte, seed uint32) murmur3.Uint128 {
+	h1, h2 := murmur3.SeedSum128(uint64(seed), uint64(seed), key)
+	return murmur3.Uint128{Hi: h2, Lo: h1}
+}
+
+var two128 = new(big.Int).Lsh(big.NewInt(1), 128)
+
+// MMH3Hash128Signed returns mmh3.hash128(key, seed, signed=True), the two's
+// complement reading of MMH3Hash128.
+func MMH3Hash128Signed(key []byte, seed uint32) *big.Int {
+	u := MMH3Hash128(key, seed)
+	b := u.Bytes()
+	v := new(big.Int).SetBytes(b[:])
+	if int64(u.Hi) < 0 {
+		v.Sub(v, two128)
+	}
+	return v
+}
+
+// MMH3HashBytes returns mmh3.hash_bytes(key, seed): the x64_128 sum in the
+// byte order of the C reference, h1 then h2, each little endian.
+func MMH3HashBytes(key []byte, seed uint32) [16]byte {
+	var b [16]byte
+	h1, h2 := murmur3.SeedSum128(uint64(seed), uint64(seed), key)
+	binary.LittleEndian.PutUint64(b[:8], h1)
+	binary.LittleEndian.PutUint64(b[8:], h2)
+	return b
+}
+
+// MurmurHash3_32 returns the ClickHouse murmurHash3_32(s), the UInt32 x86_32
+// sum with seed 0.
+func MurmurHash3_32(s []byte) uint32 {
+	return murmur3.SeedSum32(0, s)
+}
+
+// MurmurHash3_64 returns the ClickHouse murmurHash3_64(s), the UInt64 xor of
+// the two halves of the x64_128 sum with seed 0. Note that this is not Sum64,
+// which is h1 alone.
+func MurmurHash3_64(s []byte) uint64 {
+	h1, h2 := murmur3.Sum128(s)
+	return h1 ^ h2
+}
+
+// MurmurHash3_128 returns the ClickHouse murmurHash3_128(s), the
+// FixedString(16) holding the x64_128 sum with seed 0 in the byte order of
+// the C reference. hex(murmurHash3_128(s)) is its uppercase hex encoding.
+func MurmurHash3_128(s []byte) [16]byte {
+	return MMH3HashBytes(s, 0)
+}
@@ -0,0 +1,69 @@
+package compat
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	murmur3 "github.com/m3db/stackmurmur3/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// The examples of the mmh3 README.
+func TestMMH3(t *testing.T) {
+	foo := []byte("foo")
+	assert.Equal(t, int32(-156908512), MMH3Hash(foo, 0))
+	assert.Equal(t, int32(-1322301282), MMH3Hash(foo, 42))
+	assert.Equal(t, uint32(4138058784), MMH3HashUnsigned(foo, 0))
+
+	h1, h2 := MMH3Hash64(foo, 0)
+	assert.Equal(t, [2]int64{-2129773440516405919, 9128664383759220103}, [2]int64{h1, h2})
+	u1, u2 := MMH3Hash64Unsigned(foo, 0)
+	assert.Equal(t, [2]uint64{16316970633193145697, 9128664383759220103}, [2]uint64{u1, u2})
+
+	assert.Equal(t, "215966891540331383248189432718888555506", MMH3Hash128(foo, 42).Decimal())
+	assert.Equal(t, "-124315475380607080215185174712879655950", MMH3Hash128Signed(foo, 42).String())
+
+	b := MMH3HashBytes(foo, 0)
+	assert.Equal(t, "aE\xf5\x01W\x86q\xe2\x87}\xba+\xe4\x87\xaf~", string(b[:]))
+}
+
+func TestMMH3Consistency(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		key := []byte(strings.Repeat("k", i))
+		seed := uint32(i) * 0x9e3779b1
+		b := MMH3HashBytes(key, seed)
+		u := MMH3Hash128(key, seed)
+		assert.Equal(t, murmur3.AppendCanonical128(nil, uint64(seed), uint64(seed), key), b[:])
+
+		// hash128 reads the hash_bytes as a little endian integer.
+		be := u.Bytes()
+		for j := range be {
+			assert.Equal(t, b[15-j], be[j])
+		}
+		assert.Equal(t, b[:], u.AppendLE(nil))
+		s := MMH3Hash128Signed(key, seed)
+		assert.Equal(t, int64(u.Hi) < 0, s.Sign() < 0)
+		if s.Sign() >= 0 {
+			assert.Equal(t, u.Decimal(), s.String())
+		}
+
+		h1, h2 := MMH3Hash64Unsigned(key, seed)
+		assert.Equal(t, murmur3.Uint128{Hi: h2, Lo: h1}, u)
+		assert.Equal(t, uint32(MMH3Hash(key, seed)), MMH3HashUnsigned(key, seed))
+	}
+}
+
+// ClickHouse presents the same sums as mmh3 with seed 0, with its own sign
+// and half selection.
+func TestClickHouse(t *testing.T) {
+	foo := []byte("foo")
+	assert.Equal(t, uint32(4138058784), MurmurHash3_32(foo))
+	assert.Equal(t, uint64(11303473983767132390), MurmurHash3_64(foo))
+	b := MurmurHash3_128(foo)
+	assert.Equal(t, "6145F501578671E2877DBA2BE487AF7E", strings.ToUpper(hex.EncodeToString(b[:])))
+
+	assert.Equal(t, uint32(0), MurmurHash3_32(nil))
+	assert.Equal(t, uint64(0), MurmurHash3_64(nil))
+	assert.Equal(t, [16]byte{}, MurmurHash3_128(nil))
+}
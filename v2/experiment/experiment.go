@@ -0,0 +1,144 @@
+// Package experiment assigns users to experiment variations and percentage
+// rollouts exactly as the Optimizely SDKs do, so that Go services agree with
+// the web and mobile SDKs on every assignment.
+//
+// A user is placed in one of 10000 buckets by hashing the concatenation of a
+// bucketing ID, usually the user ID, and the ID of the entity being bucketed
+// into, an experiment or a group, with the 32 bit murmur3 seeded with 1:
+//
+//	bucket = floor(SeedSum32(1, bucketingID + entityID) / 2^32 * 10000)
+//
+// A traffic allocation then maps ranges of buckets to the IDs of variations,
+// or, for a mutually exclusive group, of experiments.
+package experiment
+
+import (
+	"errors"
+	"math"
+
+	murmur3 "github.com/m3db/stackmurmur3/v2"
+)
+
+const (
+	// Seed is the murmur3 seed of the Optimizely bucketing hash.
+	Seed = 1
+	// Buckets is the number of buckets users are placed in.
+	Buckets = 10000
+)
+
+// ErrInvalidAllocation is returned by Allocation.Validate for an allocation
+// whose ranges are out of order or extend past Buckets.
+var ErrInvalidAllocation = errors.New("experiment: invalid traffic allocation")
+
+// BucketValue returns the bucket in [0, Buckets) of bucketingID for the
+// entity with ID entityID. It does not allocate unless the two IDs together
+// are longer than 128 bytes.
+func BucketValue(bucketingID, entityID string) int {
+	var buf [128]byte
+	key := append(append(buf[:0], bucketingID...), entityID...)
+	h := murmur3.SeedSum32(Seed, key)
+	// The SDKs scale with floating point; h * Buckets / 2^32 is never close
+	// enough to an integer for that to round differently from this.
+	return int(uint64(h) * Buckets >> 32)
+}
+
+// Range assigns the buckets up to EndOfRange, and from the end of the
+// previous Range of its Allocation, to the entity with ID EntityID. An empty
+// EntityID leaves them unassigned.
+type Range struct {
+	EntityID   string
+	EndOfRange int
+}
+
+// Allocation is a traffic allocation: ranges of buckets in increasing order
+// of EndOfRange. Buckets past the last range are unassigned.
+type Allocation []Range
+
+// Validate returns ErrInvalidAllocation unless the ranges are in order and
+// end within [0, Buckets].
+func (a Allocation) Validate() error {
+	prev := 0
+	for _, r := range a {
+		if r.EndOfRange < prev || r.EndOfRange > Buckets {
+			return ErrInvalidAllocation
+		}
+		prev = r.EndOfRange
+	}
+	return nil
+}
+
+// Lookup returns the ID of the entity bucket is assigned to, and false if it
+// is unassigned.
+func (a Allocation) Lookup(bucket int) (entityID string, ok bool) {
+	for _, r := range a {
+		if bucket < r.EndOfRange {
+			return r.EntityID, r.EntityID != ""
+		}
+	}
+	return "", false
+}
+
+// Bucket returns the ID of the entity bucketingID is assigned to when
+// bucketed into the entity with ID parentID, and false if it is unassigned.
+func (a Allocation) Bucket(bucketingID, parentID string) (entityID string, ok bool) {
+	return a.Lookup(BucketValue(bucketingID, parentID))
+}
+
+// Split returns an Allocation that splits all buckets as evenly as possible
+// between entityIDs, in order, with earlier entities receiving the remainder.
+func Split(entityIDs ...string) Allocation {
+	if len(entityIDs) == 0 {
+		return nil
+	}
+	a := make(Allocation, len(entityIDs))
+	end := 0
+	for i, id := range entityIDs {
+		end += Buckets / len(entityIDs)
+		if i < Buckets%len(entityIDs) {
+			end++
+		}
+		a[i] = Range{EntityID: id, EndOfRange: end}
+	}
+	return a
+}
+
+// Group is a mutually exclusive group of experiments: a user is bucketed
+// into at most one of them, by the group's traffic allocation of experiment
+// IDs. This is the Optimizely "random" group policy.
+type Group struct {
+	ID         string
+	Allocation Allocation
+}
+
+// Experiment returns the ID of the experiment of g that bucketingID is
+// assigned to, and false if it is assigned to none.
+func (g *Group) Experiment(bucketingID string) (experimentID string, ok bool) {
+	return g.Allocation.Bucket(bucketingID, g.ID)
+}
+
+// Experiment is an experiment with a traffic allocation of variation IDs.
+type Experiment struct {
+	ID         string
+	Allocation Allocation
+	// Group, if not nil, is the mutually exclusive group of the experiment.
+	Group *Group
+}
+
+// Variation returns the ID of the variation of e that bucketingID is
+// assigned to, and false if it is assigned to none, including when e's group
+// assigns it to another experiment.
+func (e *Experiment) Variation(bucketingID string) (variationID string, ok bool) {
+	if e.Group != nil {
+		if id, ok := e.Group.Experiment(bucketingID); !ok || id != e.ID {
+			return "", false
+		}
+	}
+	return e.Allocation.Bucket(bucketingID, e.ID)
+}
+
+// InRollout reports whether bucketingID is within the first percent of
+// buckets for the rollout with ID rolloutID, with percent in [0, 100] at a
+// granularity of 0.01. Raising percent only ever adds users to a rollout.
+func InRollout(bucketingID, rolloutID string, percent float64) bool {
+	return BucketValue(bucketingID, rolloutID) < int(math.Round(percent*(Buckets/100)))
+}
@@ -0,0 +1,152 @@
+package experiment
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	murmur3 "github.com/m3db/stackmurmur3/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Bucket values from the bucketer tests of the Optimizely SDKs.
+var goldenBuckets = []struct {
+	bucketingID, entityID string
+	bucket                int
+}{
+	{"ppid1", "1886780721", 5254},
+	{"ppid2", "1886780721", 4299},
+	{"ppid2", "1886780722", 2434},
+	{"ppid3", "1886780721", 5439},
+	{"a very very very very very very very very very very very very very very very long ppd string", "1886780721", 6128},
+}
+
+func TestBucketValue(t *testing.T) {
+	for _, g := range goldenBuckets {
+		assert.Equal(t, g.bucket, BucketValue(g.bucketingID, g.entityID), g.bucketingID)
+	}
+
+	// The SDKs' floating point scaling.
+	for i := 0; i < 100000; i++ {
+		id := fmt.Sprint("user", i)
+		h := murmur3.SeedStringSum32(Seed, id+"exp")
+		want := int(math.Floor(float64(h) / (1 << 32) * Buckets))
+		require.Equal(t, want, BucketValue(id, "exp"), id)
+	}
+}
+
+func TestAllocation(t *testing.T) {
+	a := Allocation{{"a", 2500}, {"", 5000}, {"b", 5000}, {"c", 9000}}
+	require.NoError(t, a.Validate())
+	for _, c := range []struct {
+		bucket int
+		id     string
+	}{{0, "a"}, {2499, "a"}, {2500, ""}, {4999, ""}, {5000, "c"}, {8999, "c"}, {9000, ""}, {9999, ""}} {
+		id, ok := a.Lookup(c.bucket)
+		assert.Equal(t, c.id, id, c.bucket)
+		assert.Equal(t, c.id != "", ok, c.bucket)
+	}
+
+	id, ok := Allocation{{"var", 5254}, {"other", Buckets}}.Bucket("ppid1", "1886780721")
+	assert.True(t, ok)
+	assert.Equal(t, "other", id)
+	id, _ = Allocation{{"var", 5255}, {"other", Buckets}}.Bucket("ppid1", "1886780721")
+	assert.Equal(t, "var", id)
+
+	assert.Equal(t, ErrInvalidAllocation, Allocation{{"a", 10}, {"b", 5}}.Validate())
+	assert.Equal(t, ErrInvalidAllocation, Allocation{{"a", -1}}.Validate())
+	assert.Equal(t, ErrInvalidAllocation, Allocation{{"a", Buckets + 1}}.Validate())
+	assert.NoError(t, Allocation(nil).Validate())
+}
+
+func TestSplit(t *testing.T) {
+	assert.Nil(t, Split())
+	assert.Equal(t, Allocation{{"a", Buckets}}, Split("a"))
+	assert.Equal(t, Allocation{{"a", 3334}, {"b", 6667}, {"c", 10000}}, Split("a", "b", "c"))
+	assert.NoError(t, Split("a", "b", "c", "d", "e", "f", "g").Validate())
+
+	counts := make(map[string]int)
+	a := Split("a", "b", "c", "d")
+	for i := 0; i < 40000; i++ {
+		id, ok := a.Bucket(fmt.Sprint(i), "exp")
+		require.True(t, ok)
+		counts[id]++
+	}
+	for id, n := range counts {
+		assert.InDelta(t, 10000, n, 500, id)
+	}
+}
+
+func TestGroup(t *testing.T) {
+	g := &Group{ID: "group", Allocation: Allocation{{"e1", 3000}, {"e2", 6000}}}
+	e1 := &Experiment{ID: "e1", Allocation: Split("on", "off"), Group: g}
+	e2 := &Experiment{ID: "e2", Allocation: Split("on", "off"), Group: g}
+	var in1, in2, none int
+	for i := 0; i < 10000; i++ {
+		user := fmt.Sprint("user", i)
+		v1, ok1 := e1.Variation(user)
+		v2, ok2 := e2.Variation(user)
+		require.False(t, ok1 && ok2, user)
+		gid, ok := g.Experiment(user)
+		switch {
+		case ok1:
+			in1++
+			assert.Equal(t, "e1", gid)
+			want, _ := e1.Allocation.Bucket(user, "e1")
+			assert.Equal(t, want, v1)
+		case ok2:
+			in2++
+			assert.Equal(t, "e2", gid)
+			want, _ := e2.Allocation.Bucket(user, "e2")
+			assert.Equal(t, want, v2)
+		default:
+			none++
+			assert.False(t, ok)
+		}
+	}
+	assert.InDelta(t, 3000, in1, 300)
+	assert.InDelta(t, 3000, in2, 300)
+	assert.InDelta(t, 4000, none, 300)
+
+	solo := &Experiment{ID: "1886780721", Allocation: Split("x", "y")}
+	v, ok := solo.Variation("ppid1")
+	assert.True(t, ok)
+	assert.Equal(t, "y", v)
+}
+
+func TestInRollout(t *testing.T) {
+	// ppid1 is in bucket 5254 of 1886780721.
+	assert.False(t, InRollout("ppid1", "1886780721", 52.54))
+	assert.True(t, InRollout("ppid1", "1886780721", 52.55))
+	assert.False(t, InRollout("ppid1", "1886780721", 0))
+	assert.True(t, InRollout("ppid1", "1886780721", 100))
+
+	for i := 0; i < 1000; i++ {
+		user := fmt.Sprint(i)
+		in := false
+		for p := 0.0; p <= 100; p += 0.29 {
+			now := InRollout(user, "flag", p)
+			assert.True(t, now || !in, "%v left the rollout at %v%%", user, p)
+			in = now
+		}
+	}
+}
+
+func TestZeroAlloc(t *testing.T) {
+	e := &Experiment{ID: "exp", Allocation: Split("a", "b"), Group: &Group{ID: "g", Allocation: Split("exp")}}
+	allocs := testing.AllocsPerRun(100, func() {
+		BucketValue("4bf92f3577b34da6a3ce929d0e0e4736", "1886780721")
+		e.Variation("user")
+		InRollout("user", "flag", 12.5)
+	})
+	assert.Zero(t, allocs)
+}
+
+func BenchmarkVariation(b *testing.B) {
+	e := &Experiment{ID: "1886780721", Allocation: Split("a", "b", "c")}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		e.Variation("4bf92f3577b34da6a3ce929d0e0e4736")
+	}
+}
@@ -0,0 +1,5 @@
+package murmur3
+
+// SeedStringSum128Gen exposes the portable implementation to external tests,
+// so they can check it against the assembly on amd64.
+var SeedStringSum128Gen = seedStringSum128Gen
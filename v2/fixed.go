@@ -0,0 +1,115 @@
+package murmur3
+
+import "math/bits"
+
+// The fixed width sums below return exactly what the byte slice sums return
+// for the little endian encoding of their key, without encoding it: with the
+// length known, the block loop and tail switch reduce to straight line code
+// without bounds checks. They all inline into their callers, which
+// TestFixedWidthInlining guards. The 128 bit sums sit just under the
+// compiler's inlining budget, which is why they are written more tersely than
+// the byte slice sums; the 16 byte sums in fixed16.go do not fit it.
+
+// SeedSum32Uint32 returns SeedSum32(seed, data) for the 4 little endian bytes
+// of x.
+func SeedSum32Uint32(seed, x uint32) uint32 {
+	k1 := x * c1_32
+	k1 = bits.RotateLeft32(k1, 15)
+	k1 *= c2_32
+
+	h1 := seed ^ k1
+	h1 = bits.RotateLeft32(h1, 13)
+	h1 = h1*5 + 0xe6546b64
+
+	h1 ^= 4
+	h1 ^= h1 >> 16
+	h1 *= 0x85ebca6b
+	h1 ^= h1 >> 13
+	h1 *= 0xc2b2ae35
+	h1 ^= h1 >> 16
+	return h1
+}
+
+// Sum32Uint32 returns Sum32 of the 4 little endian bytes of x.
+func Sum32Uint32(x uint32) uint32 { return SeedSum32Uint32(0, x) }
+
+// SeedSum128Uint64 returns SeedSum128(seed1, seed2, data) for the 8 little
+// endian bytes of x.
+func SeedSum128Uint64(seed1, seed2, x uint64) (h1, h2 uint64) {
+	// An 8 byte input is all tail, and only feeds k1: the tail mix is
+	// k1 = rotl(x*c1, 31)*c2, then h1 ^= k1, and both halves take the
+	// length. Naming the steps would push the sum over the budget.
+	return finalize128(seed1^bits.RotateLeft64(x*c1_128, 31)*c2_128^8, seed2^8)
+}
+
+// Sum128Uint64 returns Sum128 of the 8 little endian bytes of x.
+func Sum128Uint64(x uint64) (h1, h2 uint64) {
+	return finalize128(bits.RotateLeft64(x*c1_128, 31)*c2_128^8, 8)
+}
+
+// SeedSum64Uint64 returns SeedSum64(seed, data) for the 8 little endian bytes
+// of x.
+func SeedSum64Uint64(seed, x uint64) uint64 {
+	h1, _ := finalize128(seed^bits.RotateLeft64(x*c1_128, 31)*c2_128^8, seed^8)
+	return h1
+}
+
+// Sum64Uint64 returns Sum64 of the 8 little endian bytes of x.
+func Sum64Uint64(x uint64) uint64 {
+	h1, _ := finalize128(bits.RotateLeft64(x*c1_128, 31)*c2_128^8, 8)
+	return h1
+}
+
+// finalize128 finishes a 128 bit sum whose length is already mixed in, as
+// the end of SeedSum128 does. It is
+//
+//	h1 += h2; h2 += h1
+//	h1 = fmix64(h1); h2 = fmix64(h2)
+//	h1 += h2; h2 += h1
+//
+// except that the loop applies fmix64 to each half in turn, swapping them,
+// because two inlined fmix64 calls would push the sums above over the
+// inlining budget.
+func finalize128(h1, h2 uint64) (uint64, uint64) {
+	h1 += h2
+	h2 += h1
+	for i := 0; i < 2; i++ {
+		// fmix64(h1), then swap so that the second pass mixes h2.
+		h1 ^= h1 >> 33
+		h1 *= 0xff51afd7ed558ccd
+		h1 ^= h1 >> 33
+		h1 *= 0xc4ceb9fe1a85ec53
+		h1 ^= h1 >> 33
+		h1, h2 = h2, h1
+	}
+	h1 += h2
+	return h1, h2 + h1
+}
+
+// Sum32Uint32s appends the Sum32Uint32 of each of xs to dst and returns the
+// extended slice.
+func Sum32Uint32s(dst, xs []uint32) []uint32 {
+	for _, x := range xs {
+		dst = append(dst, SeedSum32Uint32(0, x))
+	}
+	return dst
+}
+
+// Sum64Uint64s appends the Sum64Uint64 of each of xs to dst and returns the
+// extended slice.
+func Sum64Uint64s(dst, xs []uint64) []uint64 {
+	for _, x := range xs {
+		dst = append(dst, SeedSum64Uint64(0, x))
+	}
+	return dst
+}
+
+// Sum128Uint64s appends the Sum128Uint64 of each of xs to dst and returns the
+// extended slice.
+func Sum128Uint64s(dst []Uint128, xs []uint64) []Uint128 {
+	for _, x := range xs {
+		h1, h2 := SeedSum128Uint64(0, 0, x)
+		dst = append(dst, Uint128{h1, h2})
+	}
+	return dst
+}
@@ -0,0 +1,67 @@
+package murmur3
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// The 16 byte sums are the fixed width sums for UUIDs and other 16 byte
+// keys. Like those in fixed.go they are straight line code without bounds
+// checks, but they are not inlinable: one block of mixing plus the finalizer
+// costs about twice the compiler's inlining budget of 80, so
+// SeedSum128Bytes16 is always one plain call, into which Sum128Bytes16
+// inlines. It still skips the block loop and tail switch of SeedSum128.
+
+// SeedSum128Bytes16 returns SeedSum128(seed1, seed2, b[:]), for example for
+// a UUID key.
+func SeedSum128Bytes16(seed1, seed2 uint64, b [16]byte) (h1, h2 uint64) {
+	k1 := binary.LittleEndian.Uint64(b[:8])
+	k2 := binary.LittleEndian.Uint64(b[8:])
+	h1, h2 = seed1, seed2
+
+	// One full block and no tail.
+	k1 *= c1_128
+	k1 = bits.RotateLeft64(k1, 31)
+	k1 *= c2_128
+	h1 ^= k1
+
+	h1 = bits.RotateLeft64(h1, 27)
+	h1 += h2
+	h1 = h1*5 + 0x52dce729
+
+	k2 *= c2_128
+	k2 = bits.RotateLeft64(k2, 33)
+	k2 *= c1_128
+	h2 ^= k2
+
+	h2 = bits.RotateLeft64(h2, 31)
+	h2 += h1
+	h2 = h2*5 + 0x38495ab5
+
+	h1 ^= 16
+	h2 ^= 16
+
+	h1 += h2
+	h2 += h1
+
+	h1 = fmix64(h1)
+	h2 = fmix64(h2)
+
+	h1 += h2
+	h2 += h1
+
+	return h1, h2
+}
+
+// Sum128Bytes16 returns Sum128(b[:]).
+func Sum128Bytes16(b [16]byte) (h1, h2 uint64) { return SeedSum128Bytes16(0, 0, b) }
+
+// Sum128Bytes16s appends the Sum128Bytes16 of each of xs to dst and returns
+// the extended slice.
+func Sum128Bytes16s(dst []Uint128, xs [][16]byte) []Uint128 {
+	for i := range xs {
+		h1, h2 := SeedSum128Bytes16(0, 0, xs[i])
+		dst = append(dst, Uint128{h1, h2})
+	}
+	return dst
+}
@@ -0,0 +1,149 @@
+package murmur3
+
+import (
+	"encoding/binary"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"testing/quick"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFixedWidthSums(t *testing.T) {
+	f := func(seed32, x32 uint32, seed1, seed2, x64 uint64, b16 [16]byte) bool {
+		var b4 [4]byte
+		var b8 [8]byte
+		binary.LittleEndian.PutUint32(b4[:], x32)
+		binary.LittleEndian.PutUint64(b8[:], x64)
+
+		w1, w2 := SeedSum128(seed1, seed2, b8[:])
+		h1, h2 := SeedSum128Uint64(seed1, seed2, x64)
+		u1, u2 := Sum128(b8[:])
+		v1, v2 := Sum128Uint64(x64)
+		ok64 := h1 == w1 && h2 == w2 && v1 == u1 && v2 == u2 &&
+			SeedSum64Uint64(seed1, x64) == SeedSum64(seed1, b8[:]) &&
+			Sum64Uint64(x64) == Sum64(b8[:])
+
+		w1, w2 = SeedSum128(seed1, seed2, b16[:])
+		h1, h2 = SeedSum128Bytes16(seed1, seed2, b16)
+		u1, u2 = Sum128(b16[:])
+		v1, v2 = Sum128Bytes16(b16)
+		ok16 := h1 == w1 && h2 == w2 && v1 == u1 && v2 == u2
+
+		ok32 := SeedSum32Uint32(seed32, x32) == SeedSum32(seed32, b4[:]) &&
+			Sum32Uint32(x32) == Sum32(b4[:])
+		return ok32 && ok64 && ok16
+	}
+	require.NoError(t, quick.Check(f, nil))
+}
+
+func TestFixedWidthBatches(t *testing.T) {
+	xs32 := []uint32{0, 1, 0xffffffff}
+	xs64 := []uint64{0, 1, 1 << 63}
+	xs16 := [][16]byte{{}, {1}, {15: 0xff}}
+
+	got32 := Sum32Uint32s([]uint32{7}, xs32)
+	got64 := Sum64Uint64s(nil, xs64)
+	got128 := Sum128Uint64s(nil, xs64)
+	got16 := Sum128Bytes16s(nil, xs16)
+	require.Len(t, got32, 4)
+	assert.Equal(t, uint32(7), got32[0])
+	for i := range xs32 {
+		assert.Equal(t, Sum32Uint32(xs32[i]), got32[i+1])
+		assert.Equal(t, Sum64Uint64(xs64[i]), got64[i])
+		h1, h2 := Sum128Uint64(xs64[i])
+		assert.Equal(t, Uint128{h1, h2}, got128[i])
+		h1, h2 = Sum128Bytes16(xs16[i])
+		assert.Equal(t, Uint128{h1, h2}, got16[i])
+	}
+
+	dst := make([]uint64, 0, len(xs64))
+	allocs := testing.AllocsPerRun(100, func() {
+		dst = Sum64Uint64s(dst[:0], xs64)
+	})
+	assert.Zero(t, allocs)
+}
+
+// TestFixedWidthInlining rebuilds the package to read the compiler's
+// inlining and bounds check decisions, so it only runs when
+// MURMUR3_TEST_INLINING is set, as in CI.
+func TestFixedWidthInlining(t *testing.T) {
+	if os.Getenv("MURMUR3_TEST_INLINING") == "" {
+		t.Skip("set MURMUR3_TEST_INLINING=1 to check the compiler's inlining decisions")
+	}
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool not found")
+	}
+	out, err := exec.Command(goTool, "build",
+		"-gcflags=-m -d=ssa/check_bce/debug=1", ".").CombinedOutput()
+	require.NoError(t, err, "%s", out)
+	inlinable := make(map[string]bool)
+	for _, line := range strings.Split(string(out), "\n") {
+		if !strings.HasPrefix(line, "./fixed.go:") && !strings.HasPrefix(line, "./fixed16.go:") {
+			continue
+		}
+		assert.NotContains(t, line, "Found Is", "bounds check")
+		if _, after, ok := strings.Cut(line, ": can inline "); ok {
+			inlinable[strings.Fields(after)[0]] = true
+		}
+	}
+	for _, name := range []string{
+		"SeedSum32Uint32", "Sum32Uint32",
+		"SeedSum64Uint64", "Sum64Uint64",
+		"SeedSum128Uint64", "Sum128Uint64",
+		"Sum128Bytes16",
+	} {
+		assert.True(t, inlinable[name], "%s is not inlinable", name)
+	}
+}
+
+func BenchmarkSum128Uint64(b *testing.B) {
+	b.Run("bytes", func(b *testing.B) {
+		var buf [8]byte
+		for i := 0; i < b.N; i++ {
+			binary.LittleEndian.PutUint64(buf[:], uint64(i))
+			DoNotOptimize128[0], DoNotOptimize128[1] = Sum128(buf[:])
+		}
+	})
+	b.Run("fixed", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			DoNotOptimize128[0], DoNotOptimize128[1] = Sum128Uint64(uint64(i))
+		}
+	})
+}
+
+func BenchmarkSum128Bytes16(b *testing.B) {
+	keys := make([][16]byte, 1024)
+	for i := range keys {
+		binary.LittleEndian.PutUint64(keys[i][:], uint64(i))
+	}
+	b.Run("bytes", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			DoNotOptimize128[0], DoNotOptimize128[1] = Sum128(keys[i%len(keys)][:])
+		}
+	})
+	b.Run("fixed", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			DoNotOptimize128[0], DoNotOptimize128[1] = Sum128Bytes16(keys[i%len(keys)])
+		}
+	})
+}
+
+func BenchmarkSum32Uint32(b *testing.B) {
+	b.Run("bytes", func(b *testing.B) {
+		var buf [4]byte
+		for i := 0; i < b.N; i++ {
+			binary.LittleEndian.PutUint32(buf[:], uint32(i))
+			DoNotOptimize32 = Sum32(buf[:])
+		}
+	})
+	b.Run("fixed", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			DoNotOptimize32 = Sum32Uint32(uint32(i))
+		}
+	})
+}
@@ -0,0 +1,251 @@
+// Package frame writes and reads streams of checksummed frames, for example
+// the records of a commit log, using murmur3 x64_128 as a fast,
+// non-cryptographic integrity check.
+//
+// Every frame is laid out as follows, all integers little endian:
+//
+//	length   uint32    payload length, at most MaxPayload
+//	check    uint32    low 32 bits of h1 of SeedSum128Uint64(seed1, seed2, length)
+//	payload  [length]byte
+//	sum      [16]byte  SeedSum128(seed1, seed2, length || check || payload),
+//	                   in the byte order of the C reference
+//
+// The seeds are chosen per stream, so that frames copied from one stream into
+// another do not verify. The header check lets a reader reject a corrupt
+// length before reading the bogus payload it announces, which keeps recovery
+// by scanning for the next valid frame fast.
+package frame
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	murmur3 "github.com/m3db/stackmurmur3/v2"
+	"github.com/m3db/stackmurmur3/v2/stackmurmur3"
+)
+
+const (
+	// MaxPayload is the largest payload a frame can hold.
+	MaxPayload = 1<<28 - 1
+
+	headerSize  = 8
+	trailerSize = 16
+	// Overhead is the number of bytes a frame adds to its payload.
+	Overhead = headerSize + trailerSize
+)
+
+// ErrTooLarge is returned when writing a payload larger than MaxPayload.
+var ErrTooLarge = errors.New("frame: payload too large")
+
+// CorruptError describes a frame that failed verification.
+type CorruptError struct {
+	// Offset is the stream offset at which the frame starts.
+	Offset int64
+	// Reason says which check failed.
+	Reason string
+	// Want and Got are the stored and computed checksums of a frame whose
+	// payload checksum does not match; they are zero otherwise.
+	Want, Got murmur3.Uint128
+}
+
+func (e *CorruptError) Error() string {
+	if e.Want != e.Got {
+		return fmt.Sprintf("frame: corrupt frame at offset %d: %s: stored %v, computed %v",
+			e.Offset, e.Reason, e.Want, e.Got)
+	}
+	return fmt.Sprintf("frame: corrupt frame at offset %d: %s", e.Offset, e.Reason)
+}
+
+func headerCheck(seed1, seed2 uint64, length uint32) uint32 {
+	h1, _ := murmur3.SeedSum128Uint64(seed1, seed2, uint64(length))
+	return uint32(h1)
+}
+
+// Writer writes frames to an underlying io.Writer.
+type Writer struct {
+	w            io.Writer
+	seed1, seed2 uint64
+	buf          [headerSize + trailerSize]byte
+}
+
+// NewWriter returns a Writer writing frames seeded with seed1 and seed2 to w.
+func NewWriter(w io.Writer, seed1, seed2 uint64) *Writer {
+	return &Writer{w: w, seed1: seed1, seed2: seed2}
+}
+
+// Write writes p as a single frame, so each call is one record. It returns
+// len(p) on success.
+func (w *Writer) Write(p []byte) (int, error) {
+	if len(p) > MaxPayload {
+		return 0, ErrTooLarge
+	}
+	hdr := w.buf[:headerSize]
+	binary.LittleEndian.PutUint32(hdr[0:], uint32(len(p)))
+	binary.LittleEndian.PutUint32(hdr[4:], headerCheck(w.seed1, w.seed2, uint32(len(p))))
+
+	d := stackmurmur3.New128WithSeed(w.seed1, w.seed2)
+	d.Write(hdr)
+	d.Write(p)
+	trailer := d.SumLE(w.buf[headerSize:headerSize])
+
+	if _, err := w.w.Write(hdr); err != nil {
+		return 0, err
+	}
+	if _, err := w.w.Write(p); err != nil {
+		return 0, err
+	}
+	if _, err := w.w.Write(trailer); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Reader reads and verifies frames from an underlying io.Reader.
+type Reader struct {
+	r            io.Reader
+	seed1, seed2 uint64
+	buf          []byte // Unconsumed input is buf[start:].
+	start        int
+	off          int64 // Stream offset of buf[start].
+	err          error // Sticky error from r, returned once buf is drained.
+}
+
+// NewReader returns a Reader of frames seeded with seed1 and seed2 from r.
+func NewReader(r io.Reader, seed1, seed2 uint64) *Reader {
+	return &Reader{r: r, seed1: seed1, seed2: seed2}
+}
+
+// Offset returns the stream offset of the next frame.
+func (r *Reader) Offset() int64 { return r.off }
+
+// Next returns the payload of the next frame. The payload is only valid until
+// the next call to Next or Recover.
+//
+// At the end of the stream, Next returns io.EOF. If the next frame fails
+// verification, or the stream ends within it, Next returns a *CorruptError
+// and keeps returning it until Recover is called.
+func (r *Reader) Next() ([]byte, error) {
+	payload, n, err := r.parse()
+	if err != nil {
+		return nil, err
+	}
+	r.consume(n)
+	return payload, nil
+}
+
+// Recover skips input until the next frame that verifies, or until the end of
+// the stream, and returns the number of bytes skipped. It always skips at
+// least one byte, so it makes progress past a frame that Next rejected.
+func (r *Reader) Recover() (skipped int64, err error) {
+	for {
+		if err := r.fill(1); err != nil && r.buffered() == 0 {
+			if err == io.EOF {
+				err = nil
+			}
+			return skipped, err
+		}
+		r.consume(1)
+		skipped++
+
+		_, _, err := r.parse()
+		var corrupt *CorruptError
+		switch {
+		case err == nil, err == io.EOF:
+			return skipped, nil
+		case !errors.As(err, &corrupt):
+			return skipped, err
+		}
+	}
+}
+
+// parse verifies the frame at the current position without consuming it,
+// and returns its payload and total size.
+func (r *Reader) parse() (payload []byte, n int, err error) {
+	if err := r.fill(headerSize); r.buffered() < headerSize {
+		if err == io.EOF && r.buffered() > 0 {
+			return nil, 0, r.corrupt("truncated header")
+		}
+		return nil, 0, err
+	}
+	hdr := r.buf[r.start : r.start+headerSize]
+	length := binary.LittleEndian.Uint32(hdr[0:])
+	if length > MaxPayload || binary.LittleEndian.Uint32(hdr[4:]) != headerCheck(r.seed1, r.seed2, length) {
+		return nil, 0, r.corrupt("bad header")
+	}
+
+	n = headerSize + int(length) + trailerSize
+	if err := r.fill(n); r.buffered() < n {
+		if err == io.EOF {
+			return nil, 0, r.corrupt("truncated frame")
+		}
+		return nil, 0, err
+	}
+	frame := r.buf[r.start : r.start+n]
+	payload = frame[headerSize : headerSize+int(length)]
+
+	d := stackmurmur3.New128WithSeed(r.seed1, r.seed2)
+	d.Write(frame[:headerSize+int(length)])
+	got := d.SumUint128()
+	trailer := frame[headerSize+int(length):]
+	want := murmur3.Uint128{
+		Hi: binary.LittleEndian.Uint64(trailer[:8]),
+		Lo: binary.LittleEndian.Uint64(trailer[8:]),
+	}
+	if got != want {
+		e := r.corrupt("checksum mismatch")
+		e.Want, e.Got = want, got
+		return nil, 0, e
+	}
+	return payload, n, nil
+}
+
+func (r *Reader) corrupt(reason string) *CorruptError {
+	return &CorruptError{Offset: r.off, Reason: reason}
+}
+
+func (r *Reader) buffered() int { return len(r.buf) - r.start }
+
+func (r *Reader) consume(n int) {
+	r.start += n
+	r.off += int64(n)
+}
+
+// fill reads until at least n bytes are buffered, or returns the error that
+// prevented it.
+func (r *Reader) fill(n int) error {
+	if r.buffered() >= n {
+		return nil
+	}
+	if r.err != nil {
+		return r.err
+	}
+	if cap(r.buf)-r.start < n {
+		// Compact, growing the buffer if needed.
+		size := cap(r.buf)
+		if size < n {
+			size = n
+		}
+		if size < 4096 {
+			size = 4096
+		}
+		buf := r.buf[:0]
+		if size > cap(r.buf) {
+			buf = make([]byte, 0, size)
+		}
+		r.buf = append(buf, r.buf[r.start:]...)
+		r.start = 0
+	}
+	for r.buffered() < n {
+		m, err := r.r.Read(r.buf[len(r.buf):cap(r.buf)])
+		r.buf = r.buf[:len(r.buf)+m]
+		if err != nil {
+			r.err = err
+			if r.buffered() < n {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+package frame
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"testing/iotest"
+
+	murmur3 "github.com/m3db/stackmurmur3/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func records() [][]byte {
+	var recs [][]byte
+	for i := 0; i < 20; i++ {
+		recs = append(recs, bytes.Repeat([]byte{byte('a' + i)}, i*37))
+	}
+	return recs
+}
+
+func encode(t *testing.T, recs [][]byte) []byte {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, 1, 2)
+	for _, rec := range recs {
+		n, err := w.Write(rec)
+		require.NoError(t, err)
+		require.Equal(t, len(rec), n)
+	}
+	return buf.Bytes()
+}
+
+func readAll(t *testing.T, r *Reader) [][]byte {
+	var recs [][]byte
+	for {
+		p, err := r.Next()
+		if err == io.EOF {
+			return recs
+		}
+		require.NoError(t, err)
+		recs = append(recs, append([]byte(nil), p...))
+	}
+}
+
+func assertRecords(t *testing.T, want, got [][]byte) {
+	require.Len(t, got, len(want))
+	for i := range want {
+		assert.True(t, bytes.Equal(want[i], got[i]), "record %d", i)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	recs := records()
+	data := encode(t, recs)
+	total := 0
+	for _, rec := range recs {
+		total += len(rec) + Overhead
+	}
+	assert.Len(t, data, total)
+
+	assertRecords(t, recs, readAll(t, NewReader(iotest.OneByteReader(bytes.NewReader(data)), 1, 2)))
+
+	r := NewReader(bytes.NewReader(nil), 1, 2)
+	_, err := r.Next()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestFormat(t *testing.T) {
+	var buf bytes.Buffer
+	NewWriter(&buf, 5, 6).Write([]byte("hello"))
+	b := buf.Bytes()
+	require.Len(t, b, 5+Overhead)
+
+	h1, _ := murmur3.SeedSum128Uint64(5, 6, 5)
+	assert.Equal(t, []byte{5, 0, 0, 0}, b[:4])
+	assert.Equal(t, uint32(h1), uint32(b[4])|uint32(b[5])<<8|uint32(b[6])<<16|uint32(b[7])<<24)
+	assert.Equal(t, "hello", string(b[8:13]))
+	assert.Equal(t, murmur3.AppendCanonical128(nil, 5, 6, b[:13]), b[13:])
+}
+
+func TestChecksumMismatch(t *testing.T) {
+	recs := records()
+	data := encode(t, recs)
+	// Corrupt a payload byte of the third frame.
+	off := int64(len(recs[0]) + len(recs[1]) + 2*Overhead)
+	data[off+headerSize+1] ^= 0x40
+
+	r := NewReader(bytes.NewReader(data), 1, 2)
+	for i := 0; i < 2; i++ {
+		_, err := r.Next()
+		require.NoError(t, err)
+	}
+	_, err := r.Next()
+	var corrupt *CorruptError
+	require.True(t, errors.As(err, &corrupt), "%v", err)
+	assert.Equal(t, off, corrupt.Offset)
+	assert.Equal(t, "checksum mismatch", corrupt.Reason)
+	assert.NotEqual(t, corrupt.Want, corrupt.Got)
+	assert.Contains(t, err.Error(), fmt.Sprintf("offset %d", off))
+
+	// The error is sticky until Recover.
+	_, err2 := r.Next()
+	assert.Equal(t, err, err2)
+
+	skipped, err := r.Recover()
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(recs[2])+Overhead), skipped)
+	assertRecords(t, recs[3:], readAll(t, r))
+}
+
+func TestBadHeader(t *testing.T) {
+	data := encode(t, records())
+	data[0] ^= 0x01
+	_, err := NewReader(bytes.NewReader(data), 1, 2).Next()
+	var corrupt *CorruptError
+	require.True(t, errors.As(err, &corrupt))
+	assert.Equal(t, int64(0), corrupt.Offset)
+	assert.Equal(t, "bad header", corrupt.Reason)
+	assert.True(t, corrupt.Want.IsZero() && corrupt.Got.IsZero())
+
+	// Frames from a stream with other seeds do not verify.
+	_, err = NewReader(bytes.NewReader(encode(t, records())), 2, 1).Next()
+	assert.True(t, errors.As(err, &corrupt))
+}
+
+func TestRecoverGarbage(t *testing.T) {
+	recs := records()
+	var stream []byte
+	stream = append(stream, encode(t, recs[:5])...)
+	stream = append(stream, bytes.Repeat([]byte{0xff, 0x00, 0x13}, 100)...)
+	stream = append(stream, encode(t, recs[5:])...)
+
+	r := NewReader(bytes.NewReader(stream), 1, 2)
+	var got [][]byte
+	var skipped int64
+	for {
+		p, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		var corrupt *CorruptError
+		if errors.As(err, &corrupt) {
+			n, err := r.Recover()
+			require.NoError(t, err)
+			skipped += n
+			continue
+		}
+		require.NoError(t, err)
+		got = append(got, append([]byte(nil), p...))
+	}
+	assert.Equal(t, int64(300), skipped)
+	assertRecords(t, recs, got)
+	assert.Equal(t, int64(len(stream)), r.Offset())
+}
+
+func TestTruncated(t *testing.T) {
+	recs := records()
+	data := encode(t, recs)
+	for _, cut := range []int{3, 10, Overhead + len(recs[19]) - 1} {
+		r := NewReader(bytes.NewReader(data[:len(data)-cut]), 1, 2)
+		var err error
+		for i := 0; i < len(recs) && err == nil; i++ {
+			_, err = r.Next()
+		}
+		var corrupt *CorruptError
+		require.True(t, errors.As(err, &corrupt), "cut %d: %v", cut, err)
+		assert.Contains(t, corrupt.Reason, "truncated")
+
+		skipped, err := r.Recover()
+		require.NoError(t, err)
+		assert.Equal(t, int64(Overhead+len(recs[19])-cut), skipped)
+		_, err = r.Next()
+		assert.Equal(t, io.EOF, err)
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) { return 0, io.ErrClosedPipe }
+
+func TestWriterErrors(t *testing.T) {
+	_, err := NewWriter(io.Discard, 0, 0).Write(make([]byte, MaxPayload+1))
+	assert.Equal(t, ErrTooLarge, err)
+	_, err = NewWriter(failingWriter{}, 0, 0).Write([]byte("x"))
+	assert.Equal(t, io.ErrClosedPipe, err)
+
+	_, err = NewReader(iotest.ErrReader(io.ErrClosedPipe), 0, 0).Next()
+	assert.Equal(t, io.ErrClosedPipe, err)
+}
+
+func BenchmarkWriter(b *testing.B) {
+	payload := make([]byte, 256)
+	w := NewWriter(io.Discard, 1, 2)
+	b.SetBytes(int64(len(payload)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		w.Write(payload)
+	}
+}
+
+func BenchmarkReader(b *testing.B) {
+	payload := make([]byte, 256)
+	var buf bytes.Buffer
+	w := NewWriter(&buf, 1, 2)
+	for i := 0; i < 1000; i++ {
+		w.Write(payload)
+	}
+	data := buf.Bytes()
+	src := bytes.NewReader(data)
+	b.SetBytes(int64(len(payload)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	var r *Reader
+	for i := 0; i < b.N; i++ {
+		if i%1000 == 0 {
+			src.Reset(data)
+			r = NewReader(src, 1, 2)
+		}
+		if _, err := r.Next(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
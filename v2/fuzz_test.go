@@ -0,0 +1,118 @@
+package murmur3_test
+
+import (
+	"testing"
+
+	murmur3 "github.com/m3db/stackmurmur3/v2"
+	"github.com/m3db/stackmurmur3/v2/stackmurmur3"
+	"github.com/m3db/stackmurmur3/v2/testdata"
+)
+
+// writeChunks feeds data to write in chunks whose sizes are taken from sizes,
+// one byte per chunk, then writes whatever remains in one final chunk. Sizes
+// go up to 39 so that chunks land before, on and after block boundaries, and
+// include empty writes. The crosscheck module, whose fuzz target compares the
+// v1 module against this one, has its own copy.
+func writeChunks(data, sizes []byte, write func([]byte)) {
+	for _, s := range sizes {
+		n := int(s) % 40
+		if n > len(data) {
+			n = len(data)
+		}
+		write(data[:n])
+		data = data[n:]
+	}
+	write(data)
+}
+
+// addSeedCorpus covers every tail length of both block sizes, data crossing
+// one and two 16 byte blocks, and chunkings that split tails and blocks.
+func addSeedCorpus(f *testing.F) {
+	data := testdata.RandBytes(64)
+	for n := 0; n <= 33; n++ {
+		f.Add(uint32(0), data[:n], []byte{})
+		f.Add(uint32(n), data[:n], []byte{1})
+		f.Add(uint32(0xffffffff), data[:n], []byte{3, 0, 5})
+	}
+	f.Add(uint32(42), data, []byte{15, 1, 16, 0, 17})
+	f.Add(uint32(42), data, []byte{4, 4, 4, 4, 4})
+	f.Add(uint32(42), data, []byte{7, 9, 2, 30})
+	for _, elem := range testdata.ReferenceHashes {
+		f.Add(uint32(0), []byte(elem.S), []byte{3, 6, 12})
+	}
+}
+
+func FuzzSum32(f *testing.F) {
+	addSeedCorpus(f)
+	f.Fuzz(func(t *testing.T, seed uint32, data, sizes []byte) {
+		want := murmur3.SeedSum32(seed, data)
+		if testdata.HasCReference {
+			if c := testdata.SeedSum32(seed, data); c != want {
+				t.Fatalf("SeedSum32 = 0x%x, C reference = 0x%x", want, c)
+			}
+		}
+		if got := murmur3.SeedStringSum32(seed, string(data)); got != want {
+			t.Fatalf("SeedStringSum32 = 0x%x, want 0x%x", got, want)
+		}
+
+		h := murmur3.SeedNew32(seed)
+		writeChunks(data, sizes, func(p []byte) { h.Write(p) })
+		if got := h.Sum32(); got != want {
+			t.Fatalf("SeedNew32 streaming = 0x%x, want 0x%x", got, want)
+		}
+
+		d := stackmurmur3.New32WithSeed(seed)
+		writeChunks(data, sizes, d.Write)
+		if got := d.Sum32(); got != want {
+			t.Fatalf("stackmurmur3.Digest32 streaming = 0x%x, want 0x%x", got, want)
+		}
+	})
+}
+
+func FuzzSum128(f *testing.F) {
+	addSeedCorpus(f)
+	f.Fuzz(func(t *testing.T, seed uint32, data, sizes []byte) {
+		s := uint64(seed)
+		w1, w2 := murmur3.SeedSum128(s, s, data)
+		check := func(name string, h1, h2 uint64) {
+			t.Helper()
+			if h1 != w1 || h2 != w2 {
+				t.Fatalf("%s = 0x%x-0x%x, want 0x%x-0x%x", name, h1, h2, w1, w2)
+			}
+		}
+		if testdata.HasCReference {
+			c1, c2 := testdata.SeedSum128(seed, data)
+			check("C reference", c1, c2)
+		}
+		g1, g2 := murmur3.SeedStringSum128Gen(s, s, string(data))
+		check("generic SeedStringSum128", g1, g2)
+		s1, s2 := murmur3.SeedStringSum128(s, s, string(data))
+		check("SeedStringSum128", s1, s2)
+		if seed == 0 {
+			z1, z2 := murmur3.Sum128(data)
+			check("Sum128", z1, z2)
+		}
+
+		h := murmur3.SeedNew128(s, s)
+		writeChunks(data, sizes, func(p []byte) { h.Write(p) })
+		h1, h2 := h.Sum128()
+		check("SeedNew128 streaming", h1, h2)
+
+		h64 := murmur3.SeedNew64(s)
+		writeChunks(data, sizes, func(p []byte) { h64.Write(p) })
+		if got := h64.Sum64(); got != w1 {
+			t.Fatalf("SeedNew64 streaming = 0x%x, want 0x%x", got, w1)
+		}
+
+		d := stackmurmur3.New128WithSeed(s, s)
+		writeChunks(data, sizes, d.Write)
+		d1, d2 := d.Sum128()
+		check("stackmurmur3.Digest128 streaming", d1, d2)
+
+		d64 := stackmurmur3.New64WithSeed(s)
+		writeChunks(data, sizes, d64.Write)
+		if got := d64.Sum64(); got != w1 {
+			t.Fatalf("stackmurmur3.Digest64 streaming = 0x%x, want 0x%x", got, w1)
+		}
+	})
+}
@@ -0,0 +1,103 @@
+package murmur3
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// Make sure interfaces are correctly implemented.
+var (
+	_ Streaming128 = Hash128(nil)
+	_ OneShot128   = Sum128Func(Sum128)
+	_ OneShot128   = Seeded128{}
+)
+
+// Streaming128 is a 128 bit digest fed by successive writes. Hash128
+// implements it, and the stackmurmur3 and v1 packages provide Stream128
+// adapters for their digests.
+type Streaming128 interface {
+	io.Writer
+	Sum128() (h1, h2 uint64)
+	// Reset returns the digest to its initial, seeded state.
+	Reset()
+}
+
+// OneShot128 is a 128 bit hash of a complete input.
+type OneShot128 interface {
+	Sum128(data []byte) (h1, h2 uint64)
+}
+
+// Sum128Func adapts a one-shot sum function, such as Sum128, to OneShot128.
+type Sum128Func func(data []byte) (h1, h2 uint64)
+
+// Sum128 returns f(data).
+func (f Sum128Func) Sum128(data []byte) (h1, h2 uint64) { return f(data) }
+
+// Seeded128 is a OneShot128 computing SeedSum128 with fixed seeds.
+type Seeded128 struct {
+	Seed1, Seed2 uint64
+}
+
+// Sum128 returns SeedSum128(s.Seed1, s.Seed2, data).
+func (s Seeded128) Sum128(data []byte) (h1, h2 uint64) {
+	return SeedSum128(s.Seed1, s.Seed2, data)
+}
+
+// The helpers below are written once against Streaming128 and OneShot128.
+// Calls through a type parameter are not devirtualized, so their arguments
+// escape like those of interface calls: keys may be moved to the heap by the
+// caller, and digests should be reused across calls rather than created per
+// key. Used that way, the helpers do not allocate.
+
+// HashKey128 resets s and returns the sum of key.
+func HashKey128[S Streaming128](s S, key []byte) (h1, h2 uint64) {
+	s.Reset()
+	s.Write(key)
+	return s.Sum128()
+}
+
+// HashFields128 resets s and returns the sum of fields, each prefixed with
+// its uvarint encoded length so that, for example, ("ab", "c") and
+// ("a", "bc") hash differently.
+func HashFields128[S Streaming128](s S, fields ...[]byte) (h1, h2 uint64) {
+	s.Reset()
+	for _, f := range fields {
+		writeUvarint(s, uint64(len(f)))
+		s.Write(f)
+	}
+	return s.Sum128()
+}
+
+// SumFields128 hashes each of fields with h, and returns the Sum128 of the
+// concatenated little endian sums. Field boundaries are implied by the fixed
+// width of the sums.
+func SumFields128[H OneShot128](h H, fields ...[]byte) (h1, h2 uint64) {
+	var d digest128
+	var block [16]byte
+	for _, f := range fields {
+		f1, f2 := h.Sum128(f)
+		binary.LittleEndian.PutUint64(block[:8], f1)
+		binary.LittleEndian.PutUint64(block[8:], f2)
+		d.bmix(block[:])
+	}
+	d.clen = 16 * len(fields)
+	return d.Sum128()
+}
+
+// byteValues[i] == i. Writing single bytes out of this table, rather than out
+// of a local buffer, keeps the buffer from escaping through w.
+var byteValues = func() (t [256]byte) {
+	for i := range t {
+		t[i] = byte(i)
+	}
+	return t
+}()
+
+func writeUvarint(w io.Writer, v uint64) {
+	for v >= 0x80 {
+		b := byte(v) | 0x80
+		w.Write(byteValues[b : b+1])
+		v >>= 7
+	}
+	w.Write(byteValues[v : v+1])
+}
@@ -0,0 +1,98 @@
+package murmur3
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashKey128(t *testing.T) {
+	d := SeedNew128(1, 2)
+	d.Write([]byte("leftover state"))
+	for _, n := range []int{0, 1, 15, 16, 17, 100} {
+		key := make([]byte, n)
+		h1, h2 := HashKey128(d, key)
+		w1, w2 := SeedSum128(1, 2, key)
+		assert.Equal(t, [2]uint64{w1, w2}, [2]uint64{h1, h2})
+	}
+}
+
+func TestHashFields128(t *testing.T) {
+	d := New128()
+	a1, a2 := HashFields128(d, []byte("ab"), []byte("c"))
+	b1, b2 := HashFields128(d, []byte("a"), []byte("bc"))
+	assert.NotEqual(t, [2]uint64{a1, a2}, [2]uint64{b1, b2})
+
+	long := make([]byte, 300)
+	h1, h2 := HashFields128(d, long, []byte("x"))
+	buf := binary.AppendUvarint(nil, 300)
+	buf = append(buf, long...)
+	buf = append(append(buf, 1), 'x')
+	w1, w2 := Sum128(buf)
+	assert.Equal(t, [2]uint64{w1, w2}, [2]uint64{h1, h2})
+}
+
+func TestSumFields128(t *testing.T) {
+	fields := [][]byte{[]byte("ab"), nil, []byte("a longer field than one block")}
+	h1, h2 := SumFields128(Seeded128{3, 4}, fields...)
+
+	var buf []byte
+	for _, f := range fields {
+		f1, f2 := SeedSum128(3, 4, f)
+		buf = binary.LittleEndian.AppendUint64(buf, f1)
+		buf = binary.LittleEndian.AppendUint64(buf, f2)
+	}
+	w1, w2 := Sum128(buf)
+	assert.Equal(t, [2]uint64{w1, w2}, [2]uint64{h1, h2})
+
+	a1, a2 := SumFields128(Sum128Func(Sum128), []byte("ab"), []byte("c"))
+	b1, b2 := SumFields128(Sum128Func(Sum128), []byte("a"), []byte("bc"))
+	assert.NotEqual(t, [2]uint64{a1, a2}, [2]uint64{b1, b2})
+}
+
+func TestHasherHelpersZeroAlloc(t *testing.T) {
+	d := New128()
+	key := make([]byte, 40)
+	long := make([]byte, 1000)
+	allocs := testing.AllocsPerRun(100, func() {
+		DoNotOptimize128[0], DoNotOptimize128[1] = HashKey128(d, key)
+		DoNotOptimize128[0], DoNotOptimize128[1] = HashFields128(d, key, long, key)
+		DoNotOptimize128[0], DoNotOptimize128[1] = SumFields128(Seeded128{}, key, long)
+	})
+	assert.Zero(t, allocs)
+}
+
+func BenchmarkHashKey128(b *testing.B) {
+	key := make([]byte, 32)
+	b.Run("direct", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			DoNotOptimize128[0], DoNotOptimize128[1] = Sum128(key)
+		}
+	})
+	b.Run("Hash128", func(b *testing.B) {
+		d := New128()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			DoNotOptimize128[0], DoNotOptimize128[1] = HashKey128(d, key)
+		}
+	})
+}
+
+func BenchmarkFields128(b *testing.B) {
+	f1, f2, f3 := []byte("cpu.load"), []byte("host=a"), []byte("region=us-east-1")
+	b.Run("HashFields128", func(b *testing.B) {
+		d := New128()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			DoNotOptimize128[0], DoNotOptimize128[1] = HashFields128(d, f1, f2, f3)
+		}
+	})
+	b.Run("SumFields128", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			DoNotOptimize128[0], DoNotOptimize128[1] = SumFields128(Sum128Func(Sum128), f1, f2, f3)
+		}
+	})
+}
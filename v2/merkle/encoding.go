@@ -0,0 +1,151 @@
+package merkle
+
+import (
+	"encoding/binary"
+)
+
+const (
+	encodingMagic   = "MK"
+	encodingVersion = 1
+
+	leafEmpty   = 0
+	leafPresent = 1
+)
+
+// MarshalBinary encodes the tree for transfer to another replica. Only the
+// bounds and the leaf accumulators are written; interior nodes are recomputed
+// on decode, and empty leaves take a single byte.
+func (t *Tree) MarshalBinary() ([]byte, error) {
+	return t.AppendBinary(nil)
+}
+
+// AppendBinary appends the MarshalBinary encoding of the tree to b.
+func (t *Tree) AppendBinary(b []byte) ([]byte, error) {
+	b = append(b, encodingMagic...)
+	b = append(b, encodingVersion)
+	b = appendUint64(b, t.seed)
+	b = appendUvarint(b, uint64(t.fanout))
+	b = appendUvarint(b, uint64(len(t.bounds)))
+	for _, bound := range t.bounds {
+		b = appendUvarint(b, uint64(len(bound)))
+		b = append(b, bound...)
+	}
+	for _, l := range t.leaves {
+		if l == (leaf{}) {
+			b = append(b, leafEmpty)
+			continue
+		}
+		b = append(b, leafPresent)
+		b = appendUvarint(b, l.n)
+		b = appendUint64(b, l.s1)
+		b = appendUint64(b, l.s2)
+	}
+	return b, nil
+}
+
+// UnmarshalBinary decodes a tree encoded by MarshalBinary, replacing the
+// receiver. The decoded bounds alias data, which must not be modified while
+// the tree is in use.
+func (t *Tree) UnmarshalBinary(data []byte) error {
+	d := decoder{buf: data}
+	if string(d.next(len(encodingMagic))) != encodingMagic || d.byte() != encodingVersion {
+		return ErrCorrupt
+	}
+	seed := d.uint64()
+	fanout := d.uvarint()
+	nbounds := d.uvarint()
+	if d.err || fanout > uint64(len(data)) || nbounds > uint64(len(data)) {
+		return ErrCorrupt
+	}
+	bounds := make([][]byte, 0, nbounds)
+	for i := uint64(0); i < nbounds && !d.err; i++ {
+		n := d.uvarint()
+		if n > uint64(len(d.buf)) {
+			return ErrCorrupt
+		}
+		b := d.next(int(n))
+		bounds = append(bounds, b[:len(b):len(b)])
+	}
+	if d.err {
+		return ErrCorrupt
+	}
+
+	nt, err := NewWithSeed(seed, int(fanout), bounds)
+	if err != nil {
+		return err
+	}
+	for i := range nt.leaves {
+		switch d.byte() {
+		case leafEmpty:
+		case leafPresent:
+			nt.leaves[i].n = d.uvarint()
+			nt.leaves[i].s1 = d.uint64()
+			nt.leaves[i].s2 = d.uint64()
+		default:
+			return ErrCorrupt
+		}
+	}
+	if d.err || len(d.buf) != 0 {
+		return ErrCorrupt
+	}
+	nt.rehash()
+	*t = *nt
+	return nil
+}
+
+func appendUint64(b []byte, v uint64) []byte {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], v)
+	return append(b, buf[:]...)
+}
+
+func appendUvarint(b []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], v)
+	return append(b, buf[:n]...)
+}
+
+// decoder reads from buf, latching err on the first short read.
+type decoder struct {
+	buf []byte
+	err bool
+}
+
+func (d *decoder) next(n int) []byte {
+	if d.err || len(d.buf) < n {
+		d.err = true
+		return nil
+	}
+	b := d.buf[:n]
+	d.buf = d.buf[n:]
+	return b
+}
+
+func (d *decoder) byte() byte {
+	b := d.next(1)
+	if b == nil {
+		return 0
+	}
+	return b[0]
+}
+
+func (d *decoder) uint64() uint64 {
+	b := d.next(8)
+	if b == nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint64(b)
+}
+
+func (d *decoder) uvarint() uint64 {
+	if d.err {
+		return 0
+	}
+	v, n := binary.Uvarint(d.buf)
+	if n <= 0 {
+		d.err = true
+		return 0
+	}
+	d.buf = d.buf[n:]
+	return v
+}
@@ -0,0 +1,326 @@
+// Package merkle implements a fixed fanout hash tree over key ordered
+// entries, for detecting which key ranges differ between two replicas.
+//
+// The key space is partitioned into leaves by a sorted list of bounds that
+// both replicas agree on. Each leaf accumulates the murmur3 sums of the
+// entries that fall into it commutatively, so entries can be inserted,
+// deleted and updated one at a time without rehashing the rest of the leaf.
+// Interior nodes hash the concatenation of their children's sums.
+//
+// The tree never retains entry keys or values; only bounds are referenced,
+// and they must not be modified while the tree is in use.
+package merkle
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math/bits"
+	"sort"
+
+	murmur3 "github.com/m3db/stackmurmur3/v2"
+	"github.com/m3db/stackmurmur3/v2/stackmurmur3"
+)
+
+var (
+	// ErrFanout is returned when a tree is created with a fanout below two.
+	ErrFanout = errors.New("merkle: fanout must be at least 2")
+	// ErrBounds is returned when bounds are not strictly increasing.
+	ErrBounds = errors.New("merkle: bounds must be strictly increasing")
+	// ErrUnordered is returned by Build when entries are not key ordered.
+	ErrUnordered = errors.New("merkle: entries must be ordered by key")
+	// ErrIncompatible is returned by Diff when trees differ in shape.
+	ErrIncompatible = errors.New("merkle: trees have different seed, fanout or bounds")
+	// ErrCorrupt is returned by UnmarshalBinary for malformed input.
+	ErrCorrupt = errors.New("merkle: corrupt serialized tree")
+)
+
+// Entry is a key/value pair to be added to a tree.
+type Entry struct {
+	Key   []byte
+	Value []byte
+}
+
+// Range is a half open key range [Start, End). A nil Start is unbounded
+// below and a nil End is unbounded above.
+type Range struct {
+	Start []byte
+	End   []byte
+}
+
+// Contains reports whether key falls in r.
+func (r Range) Contains(key []byte) bool {
+	return (r.Start == nil || bytes.Compare(key, r.Start) >= 0) &&
+		(r.End == nil || bytes.Compare(key, r.End) < 0)
+}
+
+// leaf is the commutative accumulator for the entries in one key range.
+type leaf struct {
+	s1, s2 uint64 // 128 bit modular sum of entry sums (s1 is low).
+	n      uint64 // Entry count, modulo 2^64.
+}
+
+// Tree is a merkle tree over a fixed partitioning of the key space.
+type Tree struct {
+	seed   uint64
+	fanout int
+	bounds [][]byte
+	leaves []leaf
+	// levels[0] holds leaf sums; the last level holds the root alone.
+	levels [][][2]uint64
+}
+
+// New returns an empty tree with the given fanout, whose len(bounds)+1
+// leaves are split at bounds.
+func New(fanout int, bounds [][]byte) (*Tree, error) {
+	return NewWithSeed(0, fanout, bounds)
+}
+
+// NewWithSeed is like New, but seeds every hash with seed.
+func NewWithSeed(seed uint64, fanout int, bounds [][]byte) (*Tree, error) {
+	if fanout < 2 {
+		return nil, ErrFanout
+	}
+	for i := 1; i < len(bounds); i++ {
+		if bytes.Compare(bounds[i-1], bounds[i]) >= 0 {
+			return nil, ErrBounds
+		}
+	}
+	t := &Tree{seed: seed, fanout: fanout, bounds: bounds}
+	t.init()
+	return t, nil
+}
+
+// EvenBounds returns n-1 bounds splitting the key space evenly into n leaves
+// by the first two bytes of the key. It suits keys that are themselves hashes
+// or otherwise uniformly distributed; n is capped at 65536.
+func EvenBounds(n int) [][]byte {
+	if n > 1<<16 {
+		n = 1 << 16
+	}
+	if n < 1 {
+		n = 1
+	}
+	bounds := make([][]byte, 0, n-1)
+	buf := make([]byte, 2*(n-1))
+	for i := 1; i < n; i++ {
+		b := buf[2*(i-1) : 2*i : 2*i]
+		binary.BigEndian.PutUint16(b, uint16(i*(1<<16)/n))
+		bounds = append(bounds, b)
+	}
+	return bounds
+}
+
+func (t *Tree) init() {
+	n := len(t.bounds) + 1
+	t.leaves = make([]leaf, n)
+	t.levels = t.levels[:0]
+	for {
+		t.levels = append(t.levels, make([][2]uint64, n))
+		if n == 1 {
+			break
+		}
+		n = (n + t.fanout - 1) / t.fanout
+	}
+	t.rehash()
+}
+
+// rehash recomputes every node from the leaf accumulators.
+func (t *Tree) rehash() {
+	for i := range t.leaves {
+		t.levels[0][i] = t.leafSum(i)
+	}
+	for k := 1; k < len(t.levels); k++ {
+		for i := range t.levels[k] {
+			t.levels[k][i] = t.nodeSum(k, i)
+		}
+	}
+}
+
+// Reset removes all entries from the tree.
+func (t *Tree) Reset() {
+	t.init()
+}
+
+// Build replaces the contents of the tree with entries, which must be
+// ordered by key.
+func (t *Tree) Build(entries []Entry) error {
+	for i := 1; i < len(entries); i++ {
+		if bytes.Compare(entries[i-1].Key, entries[i].Key) > 0 {
+			return ErrUnordered
+		}
+	}
+	for i := range t.leaves {
+		t.leaves[i] = leaf{}
+	}
+	// Entries are ordered, so leaves can be assigned by a single merge pass.
+	li := 0
+	for _, e := range entries {
+		for li < len(t.bounds) && bytes.Compare(e.Key, t.bounds[li]) >= 0 {
+			li++
+		}
+		t.leaves[li].add(t.entrySum(e.Key, e.Value))
+	}
+	t.rehash()
+	return nil
+}
+
+// Insert adds an entry to the tree.
+func (t *Tree) Insert(key, value []byte) {
+	i := t.leafFor(key)
+	t.leaves[i].add(t.entrySum(key, value))
+	t.updatePath(i)
+}
+
+// Delete removes an entry previously inserted with the same key and value.
+func (t *Tree) Delete(key, value []byte) {
+	i := t.leafFor(key)
+	t.leaves[i].remove(t.entrySum(key, value))
+	t.updatePath(i)
+}
+
+// Update replaces the value of an existing entry.
+func (t *Tree) Update(key, oldValue, newValue []byte) {
+	i := t.leafFor(key)
+	t.leaves[i].remove(t.entrySum(key, oldValue))
+	t.leaves[i].add(t.entrySum(key, newValue))
+	t.updatePath(i)
+}
+
+// Root returns the root sum of the tree.
+func (t *Tree) Root() (h1, h2 uint64) {
+	r := t.levels[len(t.levels)-1][0]
+	return r[0], r[1]
+}
+
+// Leaves returns the number of leaves in the tree.
+func (t *Tree) Leaves() int {
+	return len(t.leaves)
+}
+
+// LeafRange returns the key range covered by leaf i.
+func (t *Tree) LeafRange(i int) Range {
+	var r Range
+	if i > 0 {
+		r.Start = t.bounds[i-1]
+	}
+	if i < len(t.bounds) {
+		r.End = t.bounds[i]
+	}
+	return r
+}
+
+// LeafFor returns the index of the leaf covering key.
+func (t *Tree) LeafFor(key []byte) int {
+	return t.leafFor(key)
+}
+
+func (t *Tree) leafFor(key []byte) int {
+	return sort.Search(len(t.bounds), func(i int) bool {
+		return bytes.Compare(key, t.bounds[i]) < 0
+	})
+}
+
+func (t *Tree) updatePath(i int) {
+	t.levels[0][i] = t.leafSum(i)
+	for k := 1; k < len(t.levels); k++ {
+		i /= t.fanout
+		t.levels[k][i] = t.nodeSum(k, i)
+	}
+}
+
+// entrySum hashes an entry without copying it: the key sum seeds the sum of
+// the value, which keeps (key, value) splits unambiguous.
+func (t *Tree) entrySum(key, value []byte) (h1, h2 uint64) {
+	k1, k2 := murmur3.SeedSum128(t.seed, t.seed, key)
+	return murmur3.SeedSum128(k1, k2, value)
+}
+
+func (t *Tree) leafSum(i int) [2]uint64 {
+	l := t.leaves[i]
+	var buf [24]byte
+	binary.LittleEndian.PutUint64(buf[0:], l.s1)
+	binary.LittleEndian.PutUint64(buf[8:], l.s2)
+	binary.LittleEndian.PutUint64(buf[16:], l.n)
+	h1, h2 := murmur3.SeedSum128(t.seed, t.seed, buf[:])
+	return [2]uint64{h1, h2}
+}
+
+func (t *Tree) nodeSum(k, i int) [2]uint64 {
+	children := t.levels[k-1]
+	lo, hi := i*t.fanout, (i+1)*t.fanout
+	if hi > len(children) {
+		hi = len(children)
+	}
+	d := stackmurmur3.New128WithSeed(t.seed, t.seed)
+	var buf [16]byte
+	for _, c := range children[lo:hi] {
+		binary.LittleEndian.PutUint64(buf[0:], c[0])
+		binary.LittleEndian.PutUint64(buf[8:], c[1])
+		d.Write(buf[:])
+	}
+	h1, h2 := d.Sum128()
+	return [2]uint64{h1, h2}
+}
+
+func (l *leaf) add(h1, h2 uint64) {
+	var c uint64
+	l.s1, c = bits.Add64(l.s1, h1, 0)
+	l.s2, _ = bits.Add64(l.s2, h2, c)
+	l.n++
+}
+
+func (l *leaf) remove(h1, h2 uint64) {
+	var b uint64
+	l.s1, b = bits.Sub64(l.s1, h1, 0)
+	l.s2, _ = bits.Sub64(l.s2, h2, b)
+	l.n--
+}
+
+func (t *Tree) compatible(o *Tree) bool {
+	if t.seed != o.seed || t.fanout != o.fanout || len(t.bounds) != len(o.bounds) {
+		return false
+	}
+	for i := range t.bounds {
+		if !bytes.Equal(t.bounds[i], o.bounds[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Diff returns the key ranges whose contents differ between a and b, in key
+// order with adjacent ranges coalesced. Only subtrees whose sums differ are
+// visited. The trees must have been created with the same seed, fanout and
+// bounds.
+func Diff(a, b *Tree) ([]Range, error) {
+	if !a.compatible(b) {
+		return nil, ErrIncompatible
+	}
+	var ranges []Range
+	last := -2
+	var walk func(k, i int)
+	walk = func(k, i int) {
+		if a.levels[k][i] == b.levels[k][i] {
+			return
+		}
+		if k == 0 {
+			if i == last+1 {
+				ranges[len(ranges)-1].End = a.LeafRange(i).End
+			} else {
+				ranges = append(ranges, a.LeafRange(i))
+			}
+			last = i
+			return
+		}
+		lo, hi := i*a.fanout, (i+1)*a.fanout
+		if hi > len(a.levels[k-1]) {
+			hi = len(a.levels[k-1])
+		}
+		for c := lo; c < hi; c++ {
+			walk(k-1, c)
+		}
+	}
+	walk(len(a.levels)-1, 0)
+	return ranges, nil
+}
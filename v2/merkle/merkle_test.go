@@ -0,0 +1,214 @@
+package merkle
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// replica is an in-process key/value store kept in sync with a tree.
+type replica struct {
+	data map[string][]byte
+	tree *Tree
+}
+
+func newReplica(t *testing.T, bounds [][]byte) *replica {
+	tree, err := NewWithSeed(42, 4, bounds)
+	require.NoError(t, err)
+	return &replica{data: make(map[string][]byte), tree: tree}
+}
+
+func (r *replica) put(key string, value []byte) {
+	if old, ok := r.data[key]; ok {
+		r.tree.Update([]byte(key), old, value)
+	} else {
+		r.tree.Insert([]byte(key), value)
+	}
+	r.data[key] = value
+}
+
+func (r *replica) del(key string) {
+	if old, ok := r.data[key]; ok {
+		r.tree.Delete([]byte(key), old)
+		delete(r.data, key)
+	}
+}
+
+func (r *replica) entries() []Entry {
+	keys := make([]string, 0, len(r.data))
+	for k := range r.data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	entries := make([]Entry, 0, len(keys))
+	for _, k := range keys {
+		entries = append(entries, Entry{Key: []byte(k), Value: r.data[k]})
+	}
+	return entries
+}
+
+func keyN(i int) string { return fmt.Sprintf("series-%05d", i) }
+
+func testBounds() [][]byte {
+	var bounds [][]byte
+	for i := 100; i < 10000; i += 100 {
+		bounds = append(bounds, []byte(keyN(i)))
+	}
+	return bounds
+}
+
+func TestDivergentReplicas(t *testing.T) {
+	bounds := testBounds()
+	a, b := newReplica(t, bounds), newReplica(t, bounds)
+	for i := 0; i < 10000; i++ {
+		v := []byte(fmt.Sprintf("v%d", i))
+		a.put(keyN(i), v)
+		b.put(keyN(i), v)
+	}
+	a1, a2 := a.tree.Root()
+	b1, b2 := b.tree.Root()
+	require.Equal(t, [2]uint64{a1, a2}, [2]uint64{b1, b2})
+
+	ranges, err := Diff(a.tree, b.tree)
+	require.NoError(t, err)
+	assert.Empty(t, ranges)
+
+	// Diverge: an update, a delete, and a write only one side has seen.
+	diverged := []string{keyN(150), keyN(151), keyN(4321), keyN(9999)}
+	b.put(keyN(150), []byte("changed"))
+	b.put(keyN(151), []byte("changed"))
+	b.del(keyN(4321))
+	a.put(keyN(9999), []byte("late write"))
+
+	ranges, err = Diff(a.tree, b.tree)
+	require.NoError(t, err)
+	require.Len(t, ranges, 3)
+	for _, k := range diverged {
+		found := false
+		for _, r := range ranges {
+			found = found || r.Contains([]byte(k))
+		}
+		assert.True(t, found, "key %s not in any differing range", k)
+	}
+	assert.Nil(t, ranges[2].End)
+
+	// Repair: copy the differing ranges from a to b and re-diff.
+	for _, r := range ranges {
+		for k := range b.data {
+			if r.Contains([]byte(k)) {
+				b.del(k)
+			}
+		}
+		for k, v := range a.data {
+			if r.Contains([]byte(k)) {
+				b.put(k, v)
+			}
+		}
+	}
+	ranges, err = Diff(a.tree, b.tree)
+	require.NoError(t, err)
+	assert.Empty(t, ranges)
+}
+
+func TestAdjacentRangesCoalesce(t *testing.T) {
+	bounds := testBounds()
+	a, b := newReplica(t, bounds), newReplica(t, bounds)
+	a.put(keyN(150), []byte("x"))
+	a.put(keyN(250), []byte("x"))
+	ranges, err := Diff(a.tree, b.tree)
+	require.NoError(t, err)
+	require.Len(t, ranges, 1)
+	assert.Equal(t, keyN(100), string(ranges[0].Start))
+	assert.Equal(t, keyN(300), string(ranges[0].End))
+}
+
+func TestBuildMatchesIncremental(t *testing.T) {
+	bounds := EvenBounds(37)
+	r := newReplica(t, bounds)
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		k := make([]byte, 8)
+		rnd.Read(k)
+		r.put(string(k), k[:rnd.Intn(8)])
+	}
+	built, err := NewWithSeed(42, 4, bounds)
+	require.NoError(t, err)
+	require.NoError(t, built.Build(r.entries()))
+
+	w1, w2 := r.tree.Root()
+	g1, g2 := built.Root()
+	assert.Equal(t, w1, g1)
+	assert.Equal(t, w2, g2)
+
+	entries := r.entries()
+	entries[0], entries[1] = entries[1], entries[0]
+	assert.Equal(t, ErrUnordered, built.Build(entries))
+}
+
+func TestEntryBoundaryUnambiguous(t *testing.T) {
+	a, err := New(2, nil)
+	require.NoError(t, err)
+	b, err := New(2, nil)
+	require.NoError(t, err)
+	a.Insert([]byte("ab"), []byte("c"))
+	b.Insert([]byte("a"), []byte("bc"))
+	ranges, err := Diff(a, b)
+	require.NoError(t, err)
+	assert.Equal(t, []Range{{}}, ranges)
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	r := newReplica(t, testBounds())
+	for i := 0; i < 500; i += 3 {
+		r.put(keyN(i), []byte("v"))
+	}
+	b, err := r.tree.MarshalBinary()
+	require.NoError(t, err)
+
+	var decoded Tree
+	require.NoError(t, decoded.UnmarshalBinary(b))
+	ranges, err := Diff(r.tree, &decoded)
+	require.NoError(t, err)
+	assert.Empty(t, ranges)
+
+	// A decoded tree keeps accepting incremental updates.
+	r.put(keyN(3), []byte("w"))
+	decoded.Update([]byte(keyN(3)), []byte("v"), []byte("w"))
+	w1, w2 := r.tree.Root()
+	g1, g2 := decoded.Root()
+	assert.Equal(t, w1, g1)
+	assert.Equal(t, w2, g2)
+
+	for n := 0; n < len(b); n++ {
+		assert.Error(t, new(Tree).UnmarshalBinary(b[:n]), "truncated at %d", n)
+	}
+}
+
+func TestIncompatible(t *testing.T) {
+	a, err := New(4, testBounds())
+	require.NoError(t, err)
+	b, err := New(8, testBounds())
+	require.NoError(t, err)
+	_, err = Diff(a, b)
+	assert.Equal(t, ErrIncompatible, err)
+
+	_, err = New(1, nil)
+	assert.Equal(t, ErrFanout, err)
+	_, err = New(2, [][]byte{[]byte("b"), []byte("a")})
+	assert.Equal(t, ErrBounds, err)
+}
+
+func TestInsertZeroAlloc(t *testing.T) {
+	tree, err := New(16, EvenBounds(1024))
+	require.NoError(t, err)
+	key, value := []byte("some-series-id"), []byte("value")
+	allocs := testing.AllocsPerRun(100, func() {
+		tree.Insert(key, value)
+		tree.Delete(key, value)
+	})
+	assert.Equal(t, 0.0, allocs)
+}
@@ -10,7 +10,6 @@
 package murmur3
 
 import (
-	"reflect"
 	"unsafe"
 )
 
@@ -62,11 +61,8 @@ func (d *digest) Reset() {
 	d.bmixer.reset()
 }
 
+// strslice returns a string sharing the memory of slice, without copying.
+// The string must not outlive modifications to slice.
 func strslice(slice []byte) string {
-	var str string
-	*(*reflect.StringHeader)(unsafe.Pointer(&str)) = reflect.StringHeader{
-		Data: ((*reflect.SliceHeader)(unsafe.Pointer(&slice))).Data,
-		Len:  len(slice),
-	}
-	return str
+	return unsafe.String(unsafe.SliceData(slice), len(slice))
 }
@@ -2,7 +2,6 @@ package murmur3
 
 import (
 	"hash"
-	"math/bits"
 )
 
 // Make sure interfaces are correctly implemented.
@@ -56,14 +55,7 @@ func (d *digest32) bmix(p []byte) (tail []byte) {
 	for len(p) >= 4 {
 		k1 := uint32(p[0]) | uint32(p[1])<<8 | uint32(p[2])<<16 | uint32(p[3])<<24
 		p = p[4:]
-
-		k1 *= c1_32
-		k1 = bits.RotateLeft32(k1, 15)
-		k1 *= c2_32
-
-		h1 ^= k1
-		h1 = bits.RotateLeft32(h1, 13)
-		h1 = h1*5 + 0xe6546b64
+		h1 = Mix32(h1, k1)
 	}
 	d.h1 = h1
 	return p
@@ -82,19 +74,7 @@ func (d *digest32) Sum32() (h1 uint32) {
 		fallthrough
 	case 1:
 		k1 ^= uint32(d.tail[0])
-		k1 *= c1_32
-		k1 = bits.RotateLeft32(k1, 15)
-		k1 *= c2_32
-		h1 ^= k1
+		h1 = MixLast32(h1, k1)
 	}
-
-	h1 ^= uint32(d.clen)
-
-	h1 ^= h1 >> 16
-	h1 *= 0x85ebca6b
-	h1 ^= h1 >> 13
-	h1 *= 0xc2b2ae35
-	h1 ^= h1 >> 16
-
-	return h1
+	return Finalize32(h1, uint32(d.clen))
 }
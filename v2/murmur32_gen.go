@@ -32,14 +32,7 @@ func SeedStringSum32(seed uint32, data string) (h1 uint32) {
 	for len(data) >= 4 {
 		k1 := uint32(data[0]) | uint32(data[1])<<8 | uint32(data[2])<<16 | uint32(data[3])<<24
 		data = data[4:]
-
-		k1 *= c1_32
-		k1 = bits.RotateLeft32(k1, 15)
-		k1 *= c2_32
-
-		h1 ^= k1
-		h1 = bits.RotateLeft32(h1, 13)
-		h1 = h1*5 + 0xe6546b64
+		h1 = Mix32(h1, k1)
 	}
 	var k1 uint32
 	switch len(data) {
@@ -51,19 +44,40 @@ func SeedStringSum32(seed uint32, data string) (h1 uint32) {
 		fallthrough
 	case 1:
 		k1 ^= uint32(data[0])
-		k1 *= c1_32
-		k1 = bits.RotateLeft32(k1, 15)
-		k1 *= c2_32
-		h1 ^= k1
+		h1 = MixLast32(h1, k1)
 	}
+	return Finalize32(h1, clen)
+}
 
-	h1 ^= uint32(clen)
+// The x86_32 steps below are exported for hashes built from them, such as
+// those of Scala's scala.util.hashing.MurmurHash3. Sum32 of data is Mix32 of
+// each little endian block, MixLast32 of the zero padded tail if any, and
+// Finalize32 with the length of data.
 
-	h1 ^= h1 >> 16
-	h1 *= 0x85ebca6b
-	h1 ^= h1 >> 13
-	h1 *= 0xc2b2ae35
-	h1 ^= h1 >> 16
+// Mix32 mixes the block k into the running hash h.
+func Mix32(h, k uint32) uint32 {
+	h = MixLast32(h, k)
+	h = bits.RotateLeft32(h, 13)
+	return h*5 + 0xe6546b64
+}
+
+// MixLast32 mixes the last block k into the running hash h. Unlike Mix32, it
+// leaves h otherwise unchanged.
+func MixLast32(h, k uint32) uint32 {
+	k *= c1_32
+	k = bits.RotateLeft32(k, 15)
+	k *= c2_32
+	return h ^ k
+}
 
-	return h1
+// Finalize32 returns the hash of length units, whose running hash is h.
+// Finalize32(h, 0) is the finalization mix alone, Scala's avalanche.
+func Finalize32(h, length uint32) uint32 {
+	h ^= length
+	h ^= h >> 16
+	h *= 0x85ebca6b
+	h ^= h >> 13
+	h *= 0xc2b2ae35
+	h ^= h >> 16
+	return h
 }
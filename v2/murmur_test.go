@@ -14,7 +14,6 @@ import (
 )
 
 var (
-	isLittleEndian   = testdata.IsLittleEndian
 	DoNotOptimize32  uint32
 	DoNotOptimize128 [2]uint64
 )
@@ -80,7 +79,7 @@ func TestQuickSum32(t *testing.T) {
 		goh1 := Sum32(data)
 		goh2 := StringSum32(string(data))
 		cpph1 := goh1
-		if isLittleEndian {
+		if testdata.HasCReference {
 			cpph1 = testdata.SeedSum32(0, data)
 		}
 		return goh1 == goh2 && goh1 == cpph1
@@ -96,7 +95,7 @@ func TestQuickSeedSum32(t *testing.T) {
 		goh2 := SeedStringSum32(seed, string(data))
 		goh3 := func() uint32 { h := SeedNew32(seed); h.Write(data); return binary.BigEndian.Uint32(h.Sum(nil)) }()
 		cpph1 := goh1
-		if isLittleEndian {
+		if testdata.HasCReference {
 			cpph1 = testdata.SeedSum32(seed, data)
 		}
 		return goh1 == goh2 && goh1 == goh3 && goh1 == cpph1
@@ -106,12 +105,31 @@ func TestQuickSeedSum32(t *testing.T) {
 	}
 }
 
+func TestQuickMix32(t *testing.T) {
+	f := func(seed uint32, data []byte) bool {
+		h := seed
+		n := len(data) / 4 * 4
+		for i := 0; i < n; i += 4 {
+			h = Mix32(h, binary.LittleEndian.Uint32(data[i:]))
+		}
+		if n < len(data) {
+			var tail [4]byte
+			copy(tail[:], data[n:])
+			h = MixLast32(h, binary.LittleEndian.Uint32(tail[:]))
+		}
+		return Finalize32(h, uint32(len(data))) == SeedSum32(seed, data)
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+}
+
 func TestQuickSum64(t *testing.T) {
 	f := func(data []byte) bool {
 		goh1 := Sum64(data)
 		goh2 := StringSum64(string(data))
 		cpph1 := goh1
-		if isLittleEndian {
+		if testdata.HasCReference {
 			cpph1 = testdata.SeedSum64(0, data)
 		}
 		return goh1 == goh2 && goh1 == cpph1
@@ -127,7 +145,7 @@ func TestQuickSeedSum64(t *testing.T) {
 		goh2 := SeedStringSum64(uint64(seed), string(data))
 		goh3 := func() uint64 { h := SeedNew64(uint64(seed)); h.Write(data); return binary.BigEndian.Uint64(h.Sum(nil)) }()
 		cpph1 := goh1
-		if isLittleEndian {
+		if testdata.HasCReference {
 			cpph1 = testdata.SeedSum64(seed, data)
 		}
 		return goh1 == goh2 && goh1 == goh3 && goh1 == cpph1
@@ -142,7 +160,7 @@ func TestQuickSum128(t *testing.T) {
 		goh1, goh2 := Sum128(data)
 		goh3, goh4 := StringSum128(string(data))
 		cpph1, cpph2 := goh1, goh2
-		if isLittleEndian {
+		if testdata.HasCReference {
 			cpph1, cpph2 = testdata.SeedSum128(0, data)
 		}
 		return goh1 == goh3 && goh2 == goh4 && goh1 == cpph1 && goh2 == cpph2
@@ -163,8 +181,8 @@ func TestQuickSeedSum128(t *testing.T) {
 			return binary.BigEndian.Uint64(sum), binary.BigEndian.Uint64(sum[8:])
 		}()
 		cpph1, cpph2 := goh1, goh2
-		if isLittleEndian {
-			testdata.SeedSum128(seed, data)
+		if testdata.HasCReference {
+			cpph1, cpph2 = testdata.SeedSum128(seed, data)
 		}
 		return goh1 == goh3 && goh2 == goh4 &&
 			goh1 == goh5 && goh2 == goh6 &&
@@ -237,7 +255,7 @@ func TestBoundaries(t *testing.T) {
 			g32h1 := Sum32(test)
 			g32h1s := SeedSum32(0, test)
 			c32h1 := g32h1
-			if isLittleEndian {
+			if testdata.HasCReference {
 				c32h1 = testdata.SeedSum32(0, test)
 			}
 			if g32h1 != c32h1 {
@@ -249,7 +267,7 @@ func TestBoundaries(t *testing.T) {
 			g64h1 := Sum64(test)
 			g64h1s := SeedSum64(0, test)
 			c64h1 := g64h1
-			if isLittleEndian {
+			if testdata.HasCReference {
 				c64h1 = testdata.SeedSum64(0, test)
 			}
 			if g64h1 != c64h1 {
@@ -261,7 +279,7 @@ func TestBoundaries(t *testing.T) {
 			g128h1, g128h2 := Sum128(test)
 			g128h1s, g128h2s := SeedSum128(0, 0, test)
 			c128h1, c128h2 := g128h1, g128h2
-			if isLittleEndian {
+			if testdata.HasCReference {
 				c128h1, c128h2 = testdata.SeedSum128(0, test)
 			}
 			if g128h1 != c128h1 {
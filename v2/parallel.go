@@ -0,0 +1,171 @@
+package murmur3
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrNegativeSize is returned by ParallelSum128ReaderAt for a negative size.
+var ErrNegativeSize = errors.New("murmur3: negative size")
+
+// DefaultParallelChunkSize is the chunk size used by the parallel tree hash
+// when a non-positive chunk size is given.
+const DefaultParallelChunkSize = 1 << 20
+
+// treeNodeSeed seeds the hashing of interior tree nodes; chunk leaves are
+// seeded by their index instead.
+const treeNodeSeed = 0xffffffffffffffff
+
+// ParallelSum128 returns a 128 bit tree hash of data, computed across
+// workers goroutines.
+//
+// This is NOT murmur3 of data: it is a distinct hash built on top of it. The
+// input is split into chunkSize pieces, the i-th piece is hashed with
+// SeedSum128(i, i, piece), and the piece sums are combined pairwise in a
+// binary tree. The result depends only on data and chunkSize, never on the
+// number of workers, so both sides of a comparison must agree on chunkSize.
+//
+// A non-positive chunkSize selects DefaultParallelChunkSize; a non-positive
+// workers count selects runtime.GOMAXPROCS(0).
+func ParallelSum128(data []byte, chunkSize, workers int) (h1, h2 uint64) {
+	if chunkSize <= 0 {
+		chunkSize = DefaultParallelChunkSize
+	}
+	sums := make([][2]uint64, numChunks(int64(len(data)), chunkSize))
+	parallelChunks(len(sums), workers, func(i int, _ []byte) error {
+		lo := i * chunkSize
+		hi := lo + chunkSize
+		if hi > len(data) {
+			hi = len(data)
+		}
+		sums[i][0], sums[i][1] = SeedSum128(uint64(i), uint64(i), data[lo:hi])
+		return nil
+	}, 0)
+	return treeSum128(sums, int64(len(data)), chunkSize)
+}
+
+// ParallelSum128ReaderAt is the io.ReaderAt version of ParallelSum128,
+// hashing the first size bytes of r. Each worker reads its own chunks, so r
+// must support concurrent ReadAt calls, as io.ReaderAt requires. A negative
+// size returns ErrNegativeSize.
+func ParallelSum128ReaderAt(r io.ReaderAt, size int64, chunkSize, workers int) (h1, h2 uint64, err error) {
+	if size < 0 {
+		return 0, 0, ErrNegativeSize
+	}
+	if chunkSize <= 0 {
+		chunkSize = DefaultParallelChunkSize
+	}
+	// A chunk is never longer than the input, so neither is a buffer.
+	bufSize := chunkSize
+	if int64(bufSize) > size {
+		bufSize = int(size)
+	}
+	sums := make([][2]uint64, numChunks(size, chunkSize))
+	err = parallelChunks(len(sums), workers, func(i int, buf []byte) error {
+		off := int64(i) * int64(chunkSize)
+		n := int64(chunkSize)
+		if off+n > size {
+			n = size - off
+		}
+		buf = buf[:n]
+		got, err := r.ReadAt(buf, off)
+		if got == len(buf) {
+			// ReadAt may report io.EOF alongside a full read of the last chunk.
+			err = nil
+		} else if err == nil || err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return err
+		}
+		sums[i][0], sums[i][1] = SeedSum128(uint64(i), uint64(i), buf)
+		return nil
+	}, bufSize)
+	if err != nil {
+		return 0, 0, err
+	}
+	h1, h2 = treeSum128(sums, size, chunkSize)
+	return h1, h2, nil
+}
+
+// numChunks returns the number of chunks for size bytes. Empty input is one
+// empty chunk, so that every input has at least one leaf.
+func numChunks(size int64, chunkSize int) int {
+	if size == 0 {
+		return 1
+	}
+	return int((size + int64(chunkSize) - 1) / int64(chunkSize))
+}
+
+// parallelChunks calls fn for every chunk index in [0, n) across workers
+// goroutines, but no more goroutines than chunks, each with its own buffer of
+// bufSize bytes, and returns the first error encountered.
+func parallelChunks(n, workers int, fn func(i int, buf []byte) error, bufSize int) error {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > n {
+		workers = n
+	}
+
+	var (
+		next    int64 = -1
+		failed  int32
+		errOnce sync.Once
+		err     error
+		wg      sync.WaitGroup
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var buf []byte
+			if bufSize > 0 {
+				buf = make([]byte, bufSize)
+			}
+			for atomic.LoadInt32(&failed) == 0 {
+				i := int(atomic.AddInt64(&next, 1))
+				if i >= n {
+					return
+				}
+				if e := fn(i, buf); e != nil {
+					errOnce.Do(func() { err = e })
+					atomic.StoreInt32(&failed, 1)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return err
+}
+
+// treeSum128 combines chunk sums pairwise, level by level, promoting an odd
+// trailing node unchanged, then binds the root to the input size and chunk
+// size.
+func treeSum128(sums [][2]uint64, size int64, chunkSize int) (h1, h2 uint64) {
+	var buf [32]byte
+	for len(sums) > 1 {
+		next := sums[:0]
+		for i := 0; i+1 < len(sums); i += 2 {
+			binary.LittleEndian.PutUint64(buf[0:], sums[i][0])
+			binary.LittleEndian.PutUint64(buf[8:], sums[i][1])
+			binary.LittleEndian.PutUint64(buf[16:], sums[i+1][0])
+			binary.LittleEndian.PutUint64(buf[24:], sums[i+1][1])
+			var s [2]uint64
+			s[0], s[1] = SeedSum128(treeNodeSeed, treeNodeSeed, buf[:])
+			next = append(next, s)
+		}
+		if len(sums)%2 == 1 {
+			next = append(next, sums[len(sums)-1])
+		}
+		sums = next
+	}
+	binary.LittleEndian.PutUint64(buf[0:], sums[0][0])
+	binary.LittleEndian.PutUint64(buf[8:], sums[0][1])
+	return SeedSum128(uint64(size), uint64(chunkSize), buf[:16])
+}
@@ -0,0 +1,112 @@
+package murmur3
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"runtime"
+	"strconv"
+	"testing"
+
+	"github.com/m3db/stackmurmur3/v2/testdata"
+)
+
+func TestParallelSum128Deterministic(t *testing.T) {
+	data := testdata.RandBytes(100003)
+	for _, chunkSize := range []int{1, 7, 16, 4096, 1 << 20} {
+		w1, w2 := ParallelSum128(data, chunkSize, 1)
+		for _, workers := range []int{0, 2, 3, 8, 64} {
+			h1, h2 := ParallelSum128(data, chunkSize, workers)
+			if h1 != w1 || h2 != w2 {
+				t.Errorf("chunk %d workers %d: 0x%x-0x%x (want 0x%x-0x%x)", chunkSize, workers, h1, h2, w1, w2)
+			}
+			r1, r2, err := ParallelSum128ReaderAt(bytes.NewReader(data), int64(len(data)), chunkSize, workers)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r1 != w1 || r2 != w2 {
+				t.Errorf("ReaderAt chunk %d workers %d: 0x%x-0x%x (want 0x%x-0x%x)", chunkSize, workers, r1, r2, w1, w2)
+			}
+		}
+	}
+}
+
+func TestParallelSum128Distinct(t *testing.T) {
+	data := testdata.RandBytes(4096)
+	seen := make(map[[2]uint64]string)
+	check := func(name string, h1, h2 uint64) {
+		if prev, ok := seen[[2]uint64{h1, h2}]; ok {
+			t.Errorf("%s collides with %s", name, prev)
+		}
+		seen[[2]uint64{h1, h2}] = name
+	}
+	h1, h2 := ParallelSum128(data, 1024, 0)
+	check("base", h1, h2)
+	h1, h2 = ParallelSum128(data, 2048, 0)
+	check("chunk size", h1, h2)
+	h1, h2 = ParallelSum128(data[:4095], 1024, 0)
+	check("truncated", h1, h2)
+	h1, h2 = ParallelSum128(nil, 1024, 0)
+	check("empty", h1, h2)
+
+	// A single chunk must still differ from the plain murmur3 sum.
+	s1, s2 := Sum128(data)
+	check("plain", s1, s2)
+	h1, h2 = ParallelSum128(data, len(data), 0)
+	check("single chunk", h1, h2)
+}
+
+type errReaderAt struct{ err error }
+
+func (r errReaderAt) ReadAt(p []byte, off int64) (int, error) { return 0, r.err }
+
+func TestParallelSum128ReaderAtErrors(t *testing.T) {
+	boom := errors.New("boom")
+	if _, _, err := ParallelSum128ReaderAt(errReaderAt{boom}, 1<<16, 1024, 4); err != boom {
+		t.Errorf("got %v, want %v", err, boom)
+	}
+	short := bytes.NewReader(make([]byte, 100))
+	if _, _, err := ParallelSum128ReaderAt(short, 200, 64, 4); err != io.ErrUnexpectedEOF {
+		t.Errorf("got %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+	if _, _, err := ParallelSum128ReaderAt(short, -1, 64, 4); err != ErrNegativeSize {
+		t.Errorf("got %v, want %v", err, ErrNegativeSize)
+	}
+}
+
+// A small input hashed with a large chunk size must not allocate a full chunk
+// buffer per worker.
+func TestParallelSum128ReaderAtSmallInput(t *testing.T) {
+	data := testdata.RandBytes(100)
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	h1, h2, err := ParallelSum128ReaderAt(bytes.NewReader(data), int64(len(data)), 64<<20, 8)
+	runtime.ReadMemStats(&after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w1, w2 := ParallelSum128(data, 64<<20, 8); h1 != w1 || h2 != w2 {
+		t.Errorf("got %x %x, want %x %x", h1, h2, w1, w2)
+	}
+	if n := after.TotalAlloc - before.TotalAlloc; n > 1<<20 {
+		t.Errorf("allocated %d bytes for a %d byte input", n, len(data))
+	}
+}
+
+func BenchmarkParallelSum128(b *testing.B) {
+	data := testdata.RandBytes(64 << 20)
+	b.Run("Sum128", func(b *testing.B) {
+		b.SetBytes(int64(len(data)))
+		for i := 0; i < b.N; i++ {
+			DoNotOptimize128[0], DoNotOptimize128[1] = Sum128(data)
+		}
+	})
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run("Workers/"+strconv.Itoa(workers), func(b *testing.B) {
+			b.SetBytes(int64(len(data)))
+			for i := 0; i < b.N; i++ {
+				DoNotOptimize128[0], DoNotOptimize128[1] = ParallelSum128(data, 0, workers)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package quality
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+)
+
+// avalancheThreshold is SMHasher's limit on the worst bias of any input bit
+// to output bit flip probability. Below a few hundred thousand samples the
+// sampling noise alone exceeds it, so the threshold widens to stay clear of
+// noise for small configurations.
+func avalancheThreshold(reps int) float64 {
+	return math.Max(0.01, 6/math.Sqrt(float64(reps)))
+}
+
+// Avalanche runs the strict avalanche criterion test: flipping any single
+// input bit must flip every output bit with probability one half.
+func Avalanche(h Hash, cfg Config) []Result {
+	rnd := newRand(cfg)
+	results := make([]Result, 0, len(cfg.AvalancheKeyLens))
+	for _, n := range cfg.AvalancheKeyLens {
+		key := make([]byte, n)
+		counts := make([]int, 8*n*h.Bits)
+		for r := 0; r < cfg.AvalancheReps; r++ {
+			rnd.Read(key)
+			lo, hi := h.sum(0, key)
+			for i := 0; i < 8*n; i++ {
+				key[i/8] ^= 1 << uint(i%8)
+				flo, fhi := h.sum(0, key)
+				key[i/8] ^= 1 << uint(i%8)
+				addFlips(counts[i*h.Bits:(i+1)*h.Bits], lo^flo, hi^fhi)
+			}
+		}
+		worst, in, out := worstBias(counts, h.Bits, cfg.AvalancheReps)
+		limit := avalancheThreshold(cfg.AvalancheReps)
+		results = append(results, Result{
+			Hash: h.Name,
+			Test: fmt.Sprintf("Avalanche/%d-byte keys", n),
+			Pass: worst <= limit,
+			Detail: fmt.Sprintf("worst bias %.3f%% (in bit %d, out bit %d, limit %.3f%%)",
+				100*worst, in, out, 100*limit),
+		})
+	}
+	return results
+}
+
+// BitIndependence runs the bit independence criterion test: for any single
+// input bit flip, whether one output bit flips must be uncorrelated with
+// whether any other output bit flips.
+func BitIndependence(h Hash, cfg Config) Result {
+	rnd := newRand(cfg)
+	n := cfg.BICKeyLen
+	inBits, outBits := 8*n, h.Bits
+	key := make([]byte, n)
+	// single[i*outBits+j] counts flips of out bit j for in bit i; pair[...]
+	// counts joint flips of out bits j < k.
+	single := make([]int, inBits*outBits)
+	pair := make([]int, inBits*outBits*outBits)
+	var set [128]int
+	for r := 0; r < cfg.BICReps; r++ {
+		rnd.Read(key)
+		lo, hi := h.sum(0, key)
+		for i := 0; i < inBits; i++ {
+			key[i/8] ^= 1 << uint(i%8)
+			flo, fhi := h.sum(0, key)
+			key[i/8] ^= 1 << uint(i%8)
+			ns := setBits(set[:0], lo^flo, hi^fhi)
+			base := i * outBits
+			for a, j := range ns {
+				single[base+j]++
+				row := (base + j) * outBits
+				for _, k := range ns[a+1:] {
+					pair[row+k]++
+				}
+			}
+		}
+	}
+
+	reps := float64(cfg.BICReps)
+	var worst float64
+	var wi, wj, wk int
+	for i := 0; i < inBits; i++ {
+		base := i * outBits
+		for j := 0; j < outBits; j++ {
+			pj := float64(single[base+j]) / reps
+			for k := j + 1; k < outBits; k++ {
+				pk := float64(single[base+k]) / reps
+				pjk := float64(pair[(base+j)*outBits+k]) / reps
+				den := math.Sqrt(pj * (1 - pj) * pk * (1 - pk))
+				if den == 0 {
+					continue
+				}
+				if c := math.Abs(pjk-pj*pk) / den; c > worst {
+					worst, wi, wj, wk = c, i, j, k
+				}
+			}
+		}
+	}
+	limit := math.Max(0.01, 7/math.Sqrt(reps))
+	return Result{
+		Hash: h.Name,
+		Test: fmt.Sprintf("BitIndependence/%d-byte keys", n),
+		Pass: worst <= limit,
+		Detail: fmt.Sprintf("worst correlation %.4f (in bit %d, out bits %d,%d, limit %.4f)",
+			worst, wi, wj, wk, limit),
+	}
+}
+
+// seedAvalanche flips each seed bit and requires every output bit to flip
+// with probability one half.
+func seedAvalanche(h Hash, cfg Config) Result {
+	rnd := newRand(cfg)
+	key := []byte("seed avalanche key")
+	counts := make([]int, 32*h.Bits)
+	for r := 0; r < cfg.SeedAvalancheReps; r++ {
+		seed := rnd.Uint32()
+		lo, hi := h.sum(seed, key)
+		for i := 0; i < 32; i++ {
+			flo, fhi := h.sum(seed^1<<uint(i), key)
+			addFlips(counts[i*h.Bits:(i+1)*h.Bits], lo^flo, hi^fhi)
+		}
+	}
+	worst, in, out := worstBias(counts, h.Bits, cfg.SeedAvalancheReps)
+	limit := avalancheThreshold(cfg.SeedAvalancheReps)
+	return Result{
+		Hash: h.Name,
+		Test: "Seed/avalanche",
+		Pass: worst <= limit,
+		Detail: fmt.Sprintf("worst bias %.3f%% (seed bit %d, out bit %d, limit %.3f%%)",
+			100*worst, in, out, 100*limit),
+	}
+}
+
+func addFlips(counts []int, dlo, dhi uint64) {
+	for dlo != 0 {
+		counts[bits.TrailingZeros64(dlo)]++
+		dlo &= dlo - 1
+	}
+	for dhi != 0 {
+		counts[64+bits.TrailingZeros64(dhi)]++
+		dhi &= dhi - 1
+	}
+}
+
+func setBits(dst []int, lo, hi uint64) []int {
+	for lo != 0 {
+		dst = append(dst, bits.TrailingZeros64(lo))
+		lo &= lo - 1
+	}
+	for hi != 0 {
+		dst = append(dst, 64+bits.TrailingZeros64(hi))
+		hi &= hi - 1
+	}
+	return dst
+}
+
+// worstBias returns the largest |2p-1| over all flip counts, and where.
+func worstBias(counts []int, outBits, reps int) (worst float64, in, out int) {
+	for idx, c := range counts {
+		if b := math.Abs(2*float64(c)/float64(reps) - 1); b > worst {
+			worst, in, out = b, idx/outBits, idx%outBits
+		}
+	}
+	return worst, in, out
+}
@@ -0,0 +1,211 @@
+package quality
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// collisionResult counts collisions among sums over the full hash width and,
+// for hashes wider than 32 bits, over the low 32 bits alone, which is what
+// truncating users observe. It reorders and then truncates sums in place.
+func collisionResults(h Hash, test string, sums [][2]uint64) []Result {
+	results := []Result{collisionResult(h.Name, test, sums, h.Bits)}
+	if h.Bits > 32 {
+		for i, s := range sums {
+			sums[i] = [2]uint64{s[0] & math.MaxUint32}
+		}
+		results = append(results, collisionResult(h.Name, test+" (low 32 bits)", sums, 32))
+	}
+	return results
+}
+
+func collisionResult(name, test string, sums [][2]uint64, bits int) Result {
+	got := countCollisions(sums)
+	want := expectedCollisions(len(sums), bits)
+	return Result{
+		Hash: name,
+		Test: test,
+		Pass: collisionsOK(got, want),
+		Detail: fmt.Sprintf("%d keys, %d collisions (expected %.2f)",
+			len(sums), got, want),
+	}
+}
+
+func countCollisions(sums [][2]uint64) int {
+	sort.Slice(sums, func(i, j int) bool {
+		if sums[i][1] != sums[j][1] {
+			return sums[i][1] < sums[j][1]
+		}
+		return sums[i][0] < sums[j][0]
+	})
+	var n int
+	for i := 1; i < len(sums); i++ {
+		if sums[i] == sums[i-1] {
+			n++
+		}
+	}
+	return n
+}
+
+// expectedCollisions returns the expected number of collisions (keys that
+// land on an already occupied value) among n random bits-wide values.
+func expectedCollisions(n, bits int) float64 {
+	fn := float64(n)
+	if bits >= 64 {
+		// The exact form below loses all precision; the birthday
+		// approximation is accurate here.
+		return fn * (fn - 1) / 2 / math.Pow(2, float64(bits))
+	}
+	m := math.Pow(2, float64(bits))
+	// n - m + m*(1-1/m)^n, computed stably.
+	return fn + m*math.Expm1(fn*math.Log1p(-1/m))
+}
+
+// collisionsOK follows SMHasher in failing a test with more than twice the
+// expected collisions, but only once the excess is also improbable for a
+// random function (Poisson tail below 1e-6), so that small expected counts do
+// not fail on noise.
+func collisionsOK(got int, want float64) bool {
+	if float64(got) <= 2*want {
+		return true
+	}
+	return poissonTail(got, want) > 1e-6
+}
+
+// poissonTail returns P(X >= k) for X ~ Poisson(lambda).
+func poissonTail(k int, lambda float64) float64 {
+	if lambda <= 0 {
+		if k <= 0 {
+			return 1
+		}
+		return 0
+	}
+	// Terms decrease past k > lambda, so a bounded sum converges quickly.
+	var p float64
+	logTerm := float64(k)*math.Log(lambda) - lambda - lgamma(k+1)
+	for i := k; i < k+1000; i++ {
+		t := math.Exp(logTerm)
+		p += t
+		if t < p*1e-12 {
+			break
+		}
+		logTerm += math.Log(lambda) - math.Log(float64(i+1))
+	}
+	return p
+}
+
+func lgamma(n int) float64 {
+	v, _ := math.Lgamma(float64(n))
+	return v
+}
+
+// Sparse hashes every key of each configured size, and counts collisions.
+func Sparse(h Hash, cfg Config) []Result {
+	var results []Result
+	for _, sk := range cfg.SparseKeys {
+		n := sk.Len
+		key := make([]byte, n)
+		sums := make([][2]uint64, 0, sparseKeyCount(8*n, sk.MaxBits))
+		var rec func(start, left int)
+		rec = func(start, left int) {
+			lo, hi := h.sum(0, key)
+			sums = append(sums, [2]uint64{lo, hi})
+			if left == 0 {
+				return
+			}
+			for i := start; i < 8*n; i++ {
+				key[i/8] ^= 1 << uint(i%8)
+				rec(i+1, left-1)
+				key[i/8] ^= 1 << uint(i%8)
+			}
+		}
+		rec(0, sk.MaxBits)
+		test := fmt.Sprintf("Sparse/%d-byte keys, <=%d bits", n, sk.MaxBits)
+		results = append(results, collisionResults(h, test, sums)...)
+	}
+	return results
+}
+
+// sparseKeyCount returns the number of keys of bits bits with at most
+// maxBits of them set.
+func sparseKeyCount(bits, maxBits int) int {
+	n, c := 0, 1
+	for k := 0; k <= maxBits && k <= bits; k++ {
+		n += c
+		c = c * (bits - k) / (k + 1)
+	}
+	return n
+}
+
+// Cyclic hashes keys made of a random cycle repeated CyclicRepeats times, and
+// counts collisions.
+func Cyclic(h Hash, cfg Config) []Result {
+	rnd := newRand(cfg)
+	var results []Result
+	for _, c := range cfg.CyclicLens {
+		key := make([]byte, c*cfg.CyclicRepeats)
+		sums := make([][2]uint64, 0, cfg.CyclicKeys)
+		for k := 0; k < cfg.CyclicKeys; k++ {
+			rnd.Read(key[:c])
+			for r := 1; r < cfg.CyclicRepeats; r++ {
+				copy(key[r*c:], key[:c])
+			}
+			lo, hi := h.sum(0, key)
+			sums = append(sums, [2]uint64{lo, hi})
+		}
+		test := fmt.Sprintf("Cyclic/%d-byte cycle x%d", c, cfg.CyclicRepeats)
+		results = append(results, collisionResults(h, test, sums)...)
+	}
+	return results
+}
+
+// TwoBytes hashes every key of each configured length that is zero except
+// for up to two bytes, and counts collisions.
+func TwoBytes(h Hash, cfg Config) []Result {
+	var results []Result
+	for _, n := range cfg.TwoBytesLens {
+		key := make([]byte, n)
+		var sums [][2]uint64
+		add := func() {
+			lo, hi := h.sum(0, key)
+			sums = append(sums, [2]uint64{lo, hi})
+		}
+		add()
+		for i := 0; i < n; i++ {
+			for a := 1; a < 256; a++ {
+				key[i] = byte(a)
+				add()
+				for j := i + 1; j < n; j++ {
+					for b := 1; b < 256; b++ {
+						key[j] = byte(b)
+						add()
+					}
+					key[j] = 0
+				}
+			}
+			key[i] = 0
+		}
+		test := fmt.Sprintf("TwoBytes/%d-byte keys", n)
+		results = append(results, collisionResults(h, test, sums)...)
+	}
+	return results
+}
+
+// SeedIndependence hashes each configured key under SeedCount sequential
+// seeds and counts collisions, then checks that flipping any seed bit
+// avalanches into the output.
+func SeedIndependence(h Hash, cfg Config) []Result {
+	var results []Result
+	for _, k := range cfg.SeedKeys {
+		key := []byte(k)
+		sums := make([][2]uint64, 0, cfg.SeedCount)
+		for s := 0; s < cfg.SeedCount; s++ {
+			lo, hi := h.sum(uint32(s), key)
+			sums = append(sums, [2]uint64{lo, hi})
+		}
+		test := fmt.Sprintf("Seed/%d-byte key", len(key))
+		results = append(results, collisionResults(h, test, sums)...)
+	}
+	return append(results, seedAvalanche(h, cfg))
+}
@@ -0,0 +1,56 @@
+package quality
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// Distribution hashes sequential and random 8 byte keys, reduces the low 64
+// bits of each sum modulo each configured bucket count, the way callers
+// shard with Sum64(key) % N, and runs a chi-square test against a uniform
+// distribution.
+func Distribution(h Hash, cfg Config) []Result {
+	rnd := newRand(cfg)
+	var results []Result
+	for _, kind := range []string{"sequential", "random"} {
+		for _, n := range cfg.Moduli {
+			if n < 2 {
+				continue
+			}
+			counts := make([]int, n)
+			var key [8]byte
+			for i := 0; i < cfg.DistributionKeys; i++ {
+				v := uint64(i)
+				if kind == "random" {
+					v = rnd.Uint64()
+				}
+				binary.LittleEndian.PutUint64(key[:], v)
+				lo, _ := h.sum(0, key[:])
+				counts[lo%n]++
+			}
+			z := chiSquareZ(counts, cfg.DistributionKeys)
+			results = append(results, Result{
+				Hash:   h.Name,
+				Test:   fmt.Sprintf("Distribution/%s mod %d", kind, n),
+				Pass:   math.Abs(z) <= 6,
+				Detail: fmt.Sprintf("%d keys, chi-square z-score %+.2f (limit 6)", cfg.DistributionKeys, z),
+			})
+		}
+	}
+	return results
+}
+
+// chiSquareZ returns the chi-square statistic of counts against a uniform
+// distribution of total keys, normalized to a z-score: for a uniform hash it
+// is approximately standard normal.
+func chiSquareZ(counts []int, total int) float64 {
+	expected := float64(total) / float64(len(counts))
+	var x2 float64
+	for _, c := range counts {
+		d := float64(c) - expected
+		x2 += d * d / expected
+	}
+	df := float64(len(counts) - 1)
+	return (x2 - df) / math.Sqrt(2*df)
+}
@@ -0,0 +1,71 @@
+package quality
+
+import (
+	murmur3 "github.com/m3db/stackmurmur3/v2"
+	"github.com/m3db/stackmurmur3/v2/stackmurmur3"
+)
+
+// SMHasher verification values of the canonical MurmurHash3 variants.
+const (
+	VerificationX86_32  uint32 = 0xB0F57EE3
+	VerificationX64_128 uint32 = 0x6384BA69
+)
+
+// Hashes returns the murmur3 implementations of this module as test
+// subjects: one-shot sums, their string versions, and streaming digests. The
+// 128 bit variants take the canonical single seed as both seed1 and seed2.
+func Hashes() []Hash {
+	return []Hash{
+		{Name: "v2.Sum32", Bits: 32, Sum: sum32, Verification: VerificationX86_32},
+		{Name: "v2.StringSum32", Bits: 32, Sum: stringSum32, Verification: VerificationX86_32},
+		{Name: "v2.Sum64", Bits: 64, Sum: sum64},
+		{Name: "v2.Sum128", Bits: 128, Sum: sum128, Verification: VerificationX64_128},
+		{Name: "v2.StringSum128", Bits: 128, Sum: stringSum128, Verification: VerificationX64_128},
+		{Name: "stackmurmur3.Digest32", Bits: 32, Sum: stackDigest32, Verification: VerificationX86_32},
+		{Name: "stackmurmur3.Digest64", Bits: 64, Sum: stackDigest64},
+		{Name: "stackmurmur3.Digest128", Bits: 128, Sum: stackDigest128, Verification: VerificationX64_128},
+	}
+}
+
+func sum32(seed uint32, key []byte) (uint64, uint64) {
+	return uint64(murmur3.SeedSum32(seed, key)), 0
+}
+
+func stringSum32(seed uint32, key []byte) (uint64, uint64) {
+	return uint64(murmur3.SeedStringSum32(seed, string(key))), 0
+}
+
+func sum64(seed uint32, key []byte) (uint64, uint64) {
+	return murmur3.SeedSum64(uint64(seed), key), 0
+}
+
+func sum128(seed uint32, key []byte) (uint64, uint64) {
+	return murmur3.SeedSum128(uint64(seed), uint64(seed), key)
+}
+
+func stringSum128(seed uint32, key []byte) (uint64, uint64) {
+	return murmur3.SeedStringSum128(uint64(seed), uint64(seed), string(key))
+}
+
+// The digests are fed in two writes to exercise their buffering.
+
+func stackDigest32(seed uint32, key []byte) (uint64, uint64) {
+	d := stackmurmur3.New32WithSeed(seed)
+	d.Write(key[:len(key)/2])
+	d.Write(key[len(key)/2:])
+	return uint64(d.Sum32()), 0
+}
+
+func stackDigest64(seed uint32, key []byte) (uint64, uint64) {
+	d := stackmurmur3.New64WithSeed(uint64(seed))
+	d.Write(key[:len(key)/2])
+	d.Write(key[len(key)/2:])
+	return d.Sum64(), 0
+}
+
+func stackDigest128(seed uint32, key []byte) (uint64, uint64) {
+	d := stackmurmur3.New128WithSeed(uint64(seed), uint64(seed))
+	d.Write(key[:len(key)/2])
+	d.Write(key[len(key)/2:])
+	return d.Sum128()
+}
@@ -0,0 +1,225 @@
+// Package quality is a pure Go port of the core SMHasher hash quality tests:
+// verification values, avalanche (strict avalanche criterion), bit
+// independence, sparse, cyclic and two-bytes key collisions, seed
+// independence and chi-square distribution across buckets.
+//
+// Tests run against any seeded hash adapted to a Hash, so the same suite
+// covers one-shot sums, streaming digests, and truncated or modular usages
+// such as Sum64 % N. The reference implementation is at
+// https://github.com/aappleby/smhasher.
+package quality
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// Func computes a hash of key with seed. Outputs up to 64 bits are returned
+// in lo; wider outputs continue in hi. Bit i of the output is bit i of lo for
+// i < 64 and bit i-64 of hi otherwise.
+type Func func(seed uint32, key []byte) (lo, hi uint64)
+
+// Hash is a hash function under test.
+type Hash struct {
+	Name string
+	// Bits is the output width, from 1 to 128.
+	Bits int
+	Sum  Func
+	// Verification is the expected SMHasher verification value, or zero if
+	// the hash has none.
+	Verification uint32
+}
+
+// Result is the outcome of one test against one hash.
+type Result struct {
+	Hash   string
+	Test   string
+	Pass   bool
+	Detail string
+}
+
+func (r Result) String() string {
+	status := "PASS"
+	if !r.Pass {
+		status = "FAIL"
+	}
+	return fmt.Sprintf("%s %-28s %-44s %s", status, r.Hash, r.Test, r.Detail)
+}
+
+// Config sizes the tests. Larger values make the statistics sharper at the
+// cost of run time and memory.
+type Config struct {
+	// RandSeed seeds the random keys and seeds, making runs reproducible.
+	RandSeed int64
+
+	// AvalancheKeyLens are the key lengths, in bytes, for the avalanche test.
+	AvalancheKeyLens []int
+	// AvalancheReps is the number of random keys per key length.
+	AvalancheReps int
+
+	// BICKeyLen is the key length, in bytes, for the bit independence test.
+	BICKeyLen int
+	// BICReps is the number of random keys for the bit independence test.
+	BICReps int
+
+	// SparseKeys are the key sizes for the sparse keys test. The number of
+	// keys grows as (8*Len)^MaxBits, so longer keys take fewer bits.
+	SparseKeys []SparseKeys
+
+	// CyclicLens are the cycle lengths, in bytes, for the cyclic keys test.
+	CyclicLens []int
+	// CyclicRepeats is the number of times a cycle repeats within a key.
+	CyclicRepeats int
+	// CyclicKeys is the number of keys per cycle length.
+	CyclicKeys int
+
+	// TwoBytesLens are the key lengths for the two-bytes keys test.
+	TwoBytesLens []int
+
+	// SeedKeys are the keys hashed under many seeds for seed independence.
+	SeedKeys []string
+	// SeedCount is the number of seeds per key.
+	SeedCount int
+	// SeedAvalancheReps is the number of random seeds for seed avalanche.
+	SeedAvalancheReps int
+
+	// Moduli are the bucket counts for the distribution test.
+	Moduli []uint64
+	// DistributionKeys is the number of keys per distribution test.
+	DistributionKeys int
+}
+
+// SparseKeys sizes the sparse keys test: every key of Len bytes with at most
+// MaxBits bits set is hashed.
+type SparseKeys struct {
+	Len, MaxBits int
+}
+
+// DefaultConfig returns a configuration close to SMHasher's own sizes, with
+// its sparse key sizes. A full run over a 128 bit hash takes minutes and a
+// few hundred MB, most of it for the 8.3 million sums of the 8 byte sparse
+// keys.
+func DefaultConfig() Config {
+	return Config{
+		RandSeed:          1,
+		AvalancheKeyLens:  []int{3, 4, 8, 16, 19, 32, 64},
+		AvalancheReps:     300000,
+		BICKeyLen:         11,
+		BICReps:           4000,
+		SparseKeys:        []SparseKeys{{4, 6}, {8, 5}, {12, 4}, {32, 3}},
+		CyclicLens:        []int{4, 8, 12, 16},
+		CyclicRepeats:     8,
+		CyclicKeys:        1000000,
+		TwoBytesLens:      []int{4, 8, 12, 16},
+		SeedKeys:          []string{"", "a", "abcdefghijklmnopqrstuvwxyz0123456789"},
+		SeedCount:         1 << 20,
+		SeedAvalancheReps: 300000,
+		Moduli:            []uint64{2, 7, 10, 100, 1000, 1024, 4099, 65536, 100003},
+		DistributionKeys:  2000000,
+	}
+}
+
+// QuickConfig returns a small configuration suited to unit tests. Its
+// thresholds widen with the smaller sample sizes, so it only catches gross
+// defects.
+func QuickConfig() Config {
+	return Config{
+		RandSeed:          1,
+		AvalancheKeyLens:  []int{3, 4, 16, 19},
+		AvalancheReps:     5000,
+		BICKeyLen:         4,
+		BICReps:           2000,
+		SparseKeys:        []SparseKeys{{4, 3}, {16, 3}},
+		CyclicLens:        []int{4, 16},
+		CyclicRepeats:     4,
+		CyclicKeys:        50000,
+		TwoBytesLens:      []int{3, 6},
+		SeedKeys:          []string{"", "abcdefghijklmnopqrstuvwxyz"},
+		SeedCount:         1 << 14,
+		SeedAvalancheReps: 5000,
+		Moduli:            []uint64{7, 100, 1024},
+		DistributionKeys:  100000,
+	}
+}
+
+// Run runs every test against h.
+func Run(h Hash, cfg Config) []Result {
+	var results []Result
+	if h.Verification != 0 {
+		results = append(results, Verification(h))
+	}
+	results = append(results, Avalanche(h, cfg)...)
+	results = append(results, BitIndependence(h, cfg))
+	results = append(results, Sparse(h, cfg)...)
+	results = append(results, Cyclic(h, cfg)...)
+	results = append(results, TwoBytes(h, cfg)...)
+	results = append(results, SeedIndependence(h, cfg)...)
+	results = append(results, Distribution(h, cfg)...)
+	return results
+}
+
+// VerificationValue computes SMHasher's verification value for h: keys of
+// the form {0, 1, ..., n-1} for n up to 255 are hashed with seed 256-n, and
+// the little endian concatenation of those hashes is hashed with seed 0.
+func VerificationValue(h Hash) uint32 {
+	n := (h.Bits + 7) / 8
+	var key [256]byte
+	hashes := make([]byte, 0, 256*n)
+	for i := 0; i < 256; i++ {
+		key[i] = byte(i)
+		lo, hi := h.Sum(uint32(256-i), key[:i])
+		hashes = appendLE(hashes, lo, hi, n)
+	}
+	lo, hi := h.Sum(0, hashes)
+	final := appendLE(nil, lo, hi, n)
+	var v uint32
+	for i := 0; i < 4 && i < len(final); i++ {
+		v |= uint32(final[i]) << (8 * uint(i))
+	}
+	return v
+}
+
+// Verification checks h's SMHasher verification value.
+func Verification(h Hash) Result {
+	got := VerificationValue(h)
+	return Result{
+		Hash:   h.Name,
+		Test:   "Verification",
+		Pass:   got == h.Verification,
+		Detail: fmt.Sprintf("0x%08X (want 0x%08X)", got, h.Verification),
+	}
+}
+
+func appendLE(b []byte, lo, hi uint64, n int) []byte {
+	for i := 0; i < n; i++ {
+		v := lo
+		if i >= 8 {
+			v = hi
+		}
+		b = append(b, byte(v>>(8*uint(i%8))))
+	}
+	return b
+}
+
+// mask truncates a hash to h.Bits.
+func (h Hash) mask(lo, hi uint64) (uint64, uint64) {
+	switch {
+	case h.Bits >= 128:
+	case h.Bits > 64:
+		hi &= 1<<uint(h.Bits-64) - 1
+	case h.Bits == 64:
+		hi = 0
+	default:
+		lo &= 1<<uint(h.Bits) - 1
+		hi = 0
+	}
+	return lo, hi
+}
+
+func (h Hash) sum(seed uint32, key []byte) (uint64, uint64) {
+	return h.mask(h.Sum(seed, key))
+}
+
+func newRand(cfg Config) *rand.Rand {
+	return rand.New(rand.NewSource(cfg.RandSeed))
+}
@@ -0,0 +1,94 @@
+package quality
+
+import (
+	"testing"
+)
+
+func TestVerificationValues(t *testing.T) {
+	for _, h := range Hashes() {
+		if h.Verification == 0 {
+			continue
+		}
+		if r := Verification(h); !r.Pass {
+			t.Error(r)
+		}
+	}
+}
+
+func TestQuick(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping hash quality suite in short mode")
+	}
+	cfg := QuickConfig()
+	for _, h := range Hashes() {
+		h := h
+		t.Run(h.Name, func(t *testing.T) {
+			for _, r := range Run(h, cfg) {
+				if !r.Pass {
+					t.Error(r)
+				}
+			}
+		})
+	}
+}
+
+// A deliberately weak hash must fail, or the thresholds are meaningless.
+func TestDetectsWeakHash(t *testing.T) {
+	weak := Hash{
+		Name: "weak",
+		Bits: 32,
+		Sum: func(seed uint32, key []byte) (uint64, uint64) {
+			h := seed
+			for _, b := range key {
+				h = h*31 + uint32(b)
+			}
+			return uint64(h), 0
+		},
+	}
+	cfg := QuickConfig()
+	for name, results := range map[string][]Result{
+		"avalanche":    Avalanche(weak, cfg),
+		"sparse":       Sparse(weak, cfg),
+		"distribution": Distribution(weak, cfg),
+	} {
+		failed := false
+		for _, r := range results {
+			failed = failed || !r.Pass
+		}
+		if !failed {
+			t.Errorf("%s: weak hash passed", name)
+		}
+	}
+}
+
+func TestExpectedCollisions(t *testing.T) {
+	for _, tt := range []struct {
+		n, bits int
+		want    float64
+	}{
+		{2, 32, 1.0 / (1 << 32)},
+		{1 << 16, 32, 0.5},
+		{1 << 20, 32, 128},
+	} {
+		got := expectedCollisions(tt.n, tt.bits)
+		if d := got/tt.want - 1; d > 0.01 || d < -0.01 {
+			t.Errorf("expectedCollisions(%d, %d) = %v, want ~%v", tt.n, tt.bits, got, tt.want)
+		}
+	}
+}
+
+func TestSparseKeyCount(t *testing.T) {
+	// The key counts SMHasher reports for its sparse key sizes.
+	for _, tt := range []struct{ bits, maxBits, want int }{
+		{32, 6, 1149017},
+		{64, 5, 8303633},
+		{96, 4, 3469497},
+		{256, 3, 2796417},
+		{8, 8, 256},
+		{8, 20, 256},
+	} {
+		if got := sparseKeyCount(tt.bits, tt.maxBits); got != tt.want {
+			t.Errorf("sparseKeyCount(%d, %d) = %d, want %d", tt.bits, tt.maxBits, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+// Package routing computes the shard that Elasticsearch, and OpenSearch,
+// route a document to, so that clients can address shards directly or
+// partition their own work the same way the cluster does.
+//
+// Elasticsearch hashes a routing value, by default the document _id, with
+// Murmur3HashFunction: the x86_32 murmur3, seeded with 0, of the string's
+// UTF-16 code units in little endian order. The shard is then
+//
+//	floorMod(hash(routing) + partitionOffset, routingNumShards) / routingFactor
+//
+// where routingFactor is routingNumShards / numberOfShards, and the partition
+// offset is nonzero only for custom routing into a routing partitioned index.
+package routing
+
+import (
+	"errors"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/m3db/stackmurmur3/v2/stackmurmur3"
+)
+
+// ErrInvalidSettings is returned by Index.Validate for shard settings that
+// Elasticsearch would reject.
+var ErrInvalidSettings = errors.New("routing: invalid index settings")
+
+// ErrRoutingRequired is returned by Index.Shard for a document without custom
+// routing in a routing partitioned index, which Elasticsearch rejects.
+var ErrRoutingRequired = errors.New("routing: routing is required for a partitioned index")
+
+// Hash returns Murmur3HashFunction.hash of the Java string holding the
+// UTF-8 string s. Invalid UTF-8 is hashed as one U+FFFD per rune that a Go
+// range loop yields for it. Hash does not allocate.
+func Hash(s string) int32 {
+	// Fast path: an ASCII string short enough for one buffer.
+	var buf [256]byte
+	if len(s) <= len(buf)/2 {
+		i := 0
+		for ; i < len(s) && s[i] < utf8.RuneSelf; i++ {
+			buf[2*i] = s[i]
+			buf[2*i+1] = 0
+		}
+		if i == len(s) {
+			d := stackmurmur3.New32()
+			d.Write(buf[:2*i])
+			return int32(d.Sum32())
+		}
+	}
+
+	d := stackmurmur3.New32()
+	n := 0
+	for _, r := range s {
+		if n > len(buf)-4 {
+			d.Write(buf[:n])
+			n = 0
+		}
+		if r < 0x10000 {
+			buf[n], buf[n+1] = byte(r), byte(r>>8)
+			n += 2
+			continue
+		}
+		r1, r2 := utf16.EncodeRune(r)
+		buf[n], buf[n+1] = byte(r1), byte(r1>>8)
+		buf[n+2], buf[n+3] = byte(r2), byte(r2>>8)
+		n += 4
+	}
+	d.Write(buf[:n])
+	return int32(d.Sum32())
+}
+
+// HashUTF16 returns Murmur3HashFunction.hash of the string with UTF-16 code
+// units units, for example one holding unpaired surrogates.
+func HashUTF16(units []uint16) int32 {
+	var buf [256]byte
+	d := stackmurmur3.New32()
+	for len(units) > 0 {
+		n := 0
+		for ; n < len(buf) && len(units) > 0; n += 2 {
+			buf[n], buf[n+1] = byte(units[0]), byte(units[0]>>8)
+			units = units[1:]
+		}
+		d.Write(buf[:n])
+	}
+	return int32(d.Sum32())
+}
+
+// DefaultRoutingNumShards returns the routing_num_shards that Elasticsearch
+// 7.0 and later give an index with numShards shards when it is not set:
+// numShards * 2^n with n = max(1, 10 - ceil(log2(numShards))), so that the
+// index can be split at least once. The result is at most 1024 for up to 512
+// shards; above that it is 2 * numShards, so 600 shards give 1200. Indices
+// created before 7.0 route over numShards.
+func DefaultRoutingNumShards(numShards int) int {
+	log2NumShards := 0
+	for 1<<log2NumShards < numShards {
+		log2NumShards++
+	}
+	numSplits := 10 - log2NumShards
+	if numSplits < 1 {
+		numSplits = 1
+	}
+	return numShards << numSplits
+}
+
+// Index holds the shard settings of an index that routing depends on.
+type Index struct {
+	// NumberOfShards is index.number_of_shards.
+	NumberOfShards int
+	// RoutingNumShards is index.number_of_routing_shards, as reported in
+	// the index metadata as routing_num_shards. Zero stands for
+	// DefaultRoutingNumShards(NumberOfShards).
+	RoutingNumShards int
+	// RoutingPartitionSize is index.routing_partition_size. Zero stands for
+	// its default, 1.
+	RoutingPartitionSize int
+}
+
+func (ix Index) routingNumShards() int {
+	if ix.RoutingNumShards == 0 {
+		return DefaultRoutingNumShards(ix.NumberOfShards)
+	}
+	return ix.RoutingNumShards
+}
+
+func (ix Index) partitionSize() int {
+	if ix.RoutingPartitionSize == 0 {
+		return 1
+	}
+	return ix.RoutingPartitionSize
+}
+
+// Validate returns ErrInvalidSettings unless NumberOfShards is positive and
+// divides the routing shards, and the routing partition size is below the
+// number of shards, or 1.
+func (ix Index) Validate() error {
+	n, rn, p := ix.NumberOfShards, ix.routingNumShards(), ix.partitionSize()
+	if n < 1 || rn < n || rn%n != 0 || p < 1 || (p > 1 && p >= n) {
+		return ErrInvalidSettings
+	}
+	return nil
+}
+
+// Shard returns the shard of the document with ID id and custom routing
+// routing, or, if routing is empty, routed by its ID. It returns
+// ErrRoutingRequired if routing is empty and ix has routing partitions. The
+// settings of ix must be valid.
+func (ix Index) Shard(id, routing string) (int, error) {
+	p := ix.partitionSize()
+	if routing == "" {
+		if p > 1 {
+			return 0, ErrRoutingRequired
+		}
+		return ix.ShardForHash(Hash(id)), nil
+	}
+	offset := int32(0)
+	if p > 1 {
+		offset = floorMod(Hash(id), p)
+	}
+	return ix.ShardForHash(Hash(routing) + offset), nil
+}
+
+// ShardForHash returns the shard of a document whose routing hash, with its
+// partition offset added, is hash.
+func (ix Index) ShardForHash(hash int32) int {
+	rn := ix.routingNumShards()
+	return int(floorMod(hash, rn)) / (rn / ix.NumberOfShards)
+}
+
+// floorMod is Java's Math.floorMod: the remainder with the sign of n.
+func floorMod(x int32, n int) int32 {
+	m := int32(int64(x) % int64(n))
+	if m < 0 {
+		m += int32(n)
+	}
+	return m
+}
@@ -0,0 +1,178 @@
+package routing
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	murmur3 "github.com/m3db/stackmurmur3/v2"
+	"github.com/m3db/stackmurmur3/v2/testdata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashKnownValues(t *testing.T) {
+	// From Elasticsearch's Murmur3HashFunctionTests.
+	for _, c := range []struct {
+		want uint32
+		s    string
+	}{
+		{0x5a0cb7c3, "hell"},
+		{0xd7c31989, "hello"},
+		{0x22ab2984, "hello w"},
+		{0xdf0ca123, "hello wo"},
+		{0xe7744d61, "hello wor"},
+		{0xe07db09c, "The quick brown fox jumps over the lazy dog"},
+		{0x4e63d2ad, "The quick brown fox jumps over the lazy cog"},
+	} {
+		assert.Equal(t, int32(c.want), Hash(c.s), c.s)
+	}
+}
+
+func TestMurmurhash3X86_32KnownValues(t *testing.T) {
+	// From Lucene's TestStringHelper, for StringHelper.murmurhash3_x86_32,
+	// which Murmur3HashFunction calls with seed 0 on the UTF-16LE bytes.
+	weapons := []byte("You want weapons? We're in a library! Books! The best weapons in the world!")
+	assert.Equal(t, uint32(0xf6a5c420), murmur3.SeedSum32(0, []byte("foo")))
+	assert.Equal(t, uint32(0xcd018ef6), murmur3.SeedSum32(16, []byte("foo")))
+	assert.Equal(t, uint32(0x111e7435), murmur3.SeedSum32(0, weapons))
+	assert.Equal(t, uint32(0x2c628cd0), murmur3.SeedSum32(3476, weapons))
+}
+
+func TestHashNonASCII(t *testing.T) {
+	// The UTF-16LE code units written out by hand: BMP characters take one,
+	// and characters beyond it a surrogate pair. The hashes are not from
+	// Elasticsearch: they were computed with the C reference
+	// MurmurHash3_x86_32 over those bytes, and are checked against it again
+	// where cgo is available.
+	for _, c := range []struct {
+		s     string
+		units []byte
+		want  uint32
+	}{
+		{"é", []byte{0xe9, 0x00}, 0x41e915ff},
+		{"日本", []byte{0xe5, 0x65, 0x2c, 0x67}, 0xa4a1f0f3},
+		{"😀", []byte{0x3d, 0xd8, 0x00, 0xde}, 0x56065e39},
+		{"id-𝄞-1", []byte{'i', 0, 'd', 0, '-', 0, 0x34, 0xd8, 0x1e, 0xdd, '-', 0, '1', 0}, 0x7da0fb53},
+		{"\xff", []byte{0xfd, 0xff}, 0x93fcaea1},
+	} {
+		assert.Equal(t, c.want, murmur3.SeedSum32(0, c.units), c.s)
+		if testdata.HasCReference {
+			assert.Equal(t, c.want, testdata.SeedSum32(0, c.units), c.s)
+		}
+		assert.Equal(t, int32(c.want), Hash(c.s), c.s)
+	}
+
+	// Long strings take several buffers.
+	for _, s := range []string{
+		strings.Repeat("a", 129),
+		strings.Repeat("é😀x", 100),
+		strings.Repeat("x", 127) + "😀" + strings.Repeat("y", 300),
+	} {
+		units := utf16.Encode([]rune(s))
+		b := make([]byte, 0, 2*len(units))
+		for _, u := range units {
+			b = append(b, byte(u), byte(u>>8))
+		}
+		want := int32(murmur3.SeedSum32(0, b))
+		assert.Equal(t, want, Hash(s))
+		assert.Equal(t, want, HashUTF16(units))
+	}
+
+	// An unpaired surrogate, which only a Java string can hold.
+	assert.Equal(t, int32(murmur3.SeedSum32(0, []byte{'a', 0, 0x3d, 0xd8})), HashUTF16([]uint16{'a', 0xd83d}))
+	assert.Equal(t, Hash(""), HashUTF16(nil))
+}
+
+func TestDefaultRoutingNumShards(t *testing.T) {
+	for n, want := range map[int]int{1: 1024, 2: 1024, 3: 768, 5: 640, 30: 960, 512: 1024, 600: 1200, 1024: 2048} {
+		assert.Equal(t, want, DefaultRoutingNumShards(n), n)
+	}
+}
+
+func TestShard(t *testing.T) {
+	// hash("hell") is 1510782915 and hash("hello") is -675079799.
+	for _, c := range []struct {
+		ix          Index
+		hell, hello int
+	}{
+		{Index{NumberOfShards: 1}, 0, 0},
+		{Index{NumberOfShards: 5}, 1, 4},
+		{Index{NumberOfShards: 3}, 0, 2},
+		{Index{NumberOfShards: 2, RoutingNumShards: 2}, 1, 1},
+		{Index{NumberOfShards: 30, RoutingNumShards: 60}, 7, 0},
+	} {
+		require.NoError(t, c.ix.Validate())
+		assertShard(t, c.hell, c.ix, "hell", "")
+		assertShard(t, c.hello, c.ix, "hello", "")
+		// Custom routing replaces the ID.
+		assertShard(t, c.hello, c.ix, "hell", "hello")
+	}
+
+	// A partitioned index offsets the routing hash by the ID's hash.
+	ix := Index{NumberOfShards: 8, RoutingNumShards: 8, RoutingPartitionSize: 3}
+	require.NoError(t, ix.Validate())
+	base, err := ix.Shard("x", "user-1")
+	require.NoError(t, err)
+	seen := make(map[int]bool)
+	for i := 0; i < 100; i++ {
+		id := strings.Repeat("i", i+1)
+		s, err := ix.Shard(id, "user-1")
+		require.NoError(t, err)
+		assert.Equal(t, (base+int(floorMod(Hash(id), 3))-int(floorMod(Hash("x"), 3))+8)%8, s, id)
+		seen[s] = true
+	}
+	assert.Len(t, seen, 3)
+	_, err = ix.Shard("hell", "")
+	assert.Equal(t, ErrRoutingRequired, err)
+	assertShard(t, ix.ShardForHash(Hash("hell")), Index{NumberOfShards: 8, RoutingNumShards: 8}, "hell", "")
+}
+
+func assertShard(t *testing.T, want int, ix Index, id, routing string) {
+	t.Helper()
+	got, err := ix.Shard(id, routing)
+	require.NoError(t, err, ix)
+	assert.Equal(t, want, got, ix)
+}
+
+func TestValidate(t *testing.T) {
+	for _, ix := range []Index{
+		{},
+		{NumberOfShards: -1},
+		{NumberOfShards: 3, RoutingNumShards: 8},
+		{NumberOfShards: 4, RoutingNumShards: 2},
+		{NumberOfShards: 4, RoutingPartitionSize: 4},
+		{NumberOfShards: 4, RoutingPartitionSize: -1},
+	} {
+		assert.Equal(t, ErrInvalidSettings, ix.Validate(), ix)
+	}
+	assert.NoError(t, Index{NumberOfShards: 1, RoutingPartitionSize: 1}.Validate())
+}
+
+func TestFloorMod(t *testing.T) {
+	assert.Equal(t, int32(3), floorMod(-5, 4))
+	assert.Equal(t, int32(1), floorMod(5, 4))
+	assert.Equal(t, int32(0), floorMod(-8, 4))
+	assert.Equal(t, int32(0), floorMod(-1<<31, 1024))
+	assert.Equal(t, int32(1023), floorMod(1<<31-1, 1024))
+}
+
+func TestZeroAlloc(t *testing.T) {
+	ix := Index{NumberOfShards: 5}
+	long := strings.Repeat("é😀x", 100)
+	allocs := testing.AllocsPerRun(100, func() {
+		Hash("4bf92f3577b34da6a3ce929d0e0e4736")
+		Hash(long)
+		HashUTF16([]uint16{'a', 0xd83d})
+		ix.Shard("doc-1", "user-1")
+	})
+	assert.Zero(t, allocs)
+}
+
+func BenchmarkShard(b *testing.B) {
+	ix := Index{NumberOfShards: 5}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ix.Shard("4bf92f3577b34da6a3ce929d0e0e4736", "")
+	}
+}
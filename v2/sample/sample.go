@@ -0,0 +1,272 @@
+// Package sample makes deterministic keep or drop decisions for traces, logs
+// or any other items with an ID, so that every service that sees an item
+// agrees on whether to keep it without coordinating.
+//
+// Decisions follow OpenTelemetry's consistent probability sampling. An item's
+// randomness R is 56 bits of Sum64 of its ID, and a sampling probability p is
+// expressed as a rejection threshold T = round((1-p) * 2^56): the item is kept
+// if and only if R >= T. Thresholds encode as the "th" value of the "ot"
+// tracestate entry, and R can be propagated as its "rv" value, so that
+// OpenTelemetry samplers downstream make the same decisions.
+//
+// Because decisions only compare R with T, lowering a threshold keeps every
+// item that the higher threshold kept, and only adds items it dropped.
+package sample
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	murmur3 "github.com/m3db/stackmurmur3/v2"
+)
+
+const (
+	randomnessBits = 56
+	// MaxRandomness is the largest randomness value.
+	MaxRandomness   = 1<<randomnessBits - 1
+	thresholdDigits = randomnessBits / 4
+)
+
+// ErrInvalidThreshold is returned when parsing a malformed threshold, or when
+// computing one from a probability outside [0, 1].
+var ErrInvalidThreshold = errors.New("sample: invalid threshold")
+
+// Randomness returns the 56 bit randomness of the item with ID id: the low 56
+// bits of Sum64(id).
+func Randomness(id []byte) uint64 {
+	return murmur3.Sum64(id) & MaxRandomness
+}
+
+// StringRandomness is the string version of Randomness.
+func StringRandomness(id string) uint64 {
+	return murmur3.StringSum64(id) & MaxRandomness
+}
+
+// AppendRandomness appends the 14 hex digit tracestate "rv" encoding of the
+// randomness r to dst.
+func AppendRandomness(dst []byte, r uint64) []byte {
+	return appendHex(dst, r&MaxRandomness)
+}
+
+// Threshold is a rejection threshold in [AlwaysSample, NeverSample]. Items
+// whose randomness is at least the threshold are kept.
+type Threshold uint64
+
+const (
+	// AlwaysSample keeps every item.
+	AlwaysSample Threshold = 0
+	// NeverSample drops every item. It has no tracestate encoding.
+	NeverSample Threshold = 1 << randomnessBits
+)
+
+// ThresholdFor returns the threshold that keeps items with probability p,
+// rounded to the nearest threshold. Any p > 0 keeps some items.
+func ThresholdFor(p float64) (Threshold, error) {
+	if !(p >= 0 && p <= 1) {
+		return 0, ErrInvalidThreshold
+	}
+	if p == 0 {
+		return NeverSample, nil
+	}
+	// Rounding p rather than 1-p keeps small probabilities precise.
+	t := NeverSample - Threshold(math.Round(p*(1<<randomnessBits)))
+	if t == NeverSample {
+		t--
+	}
+	return t, nil
+}
+
+// Probability returns the probability of keeping an item.
+func (t Threshold) Probability() float64 {
+	if t >= NeverSample {
+		return 0
+	}
+	return float64(NeverSample-t) / (1 << randomnessBits)
+}
+
+// Keep reports whether to keep the item with ID id.
+func (t Threshold) Keep(id []byte) bool {
+	return Randomness(id) >= uint64(t)
+}
+
+// KeepString is the string version of Keep.
+func (t Threshold) KeepString(id string) bool {
+	return StringRandomness(id) >= uint64(t)
+}
+
+// KeepRandomness reports whether to keep an item with randomness r, for
+// example one received in a tracestate "rv" value.
+func (t Threshold) KeepRandomness(r uint64) bool {
+	return r >= uint64(t)
+}
+
+// String returns the tracestate "th" encoding of t: up to 14 hex digits with
+// trailing zeros removed, or "0" for AlwaysSample. NeverSample encodes as "".
+func (t Threshold) String() string {
+	var buf [thresholdDigits]byte
+	return string(t.Append(buf[:0]))
+}
+
+// Append appends the encoding returned by String to dst.
+func (t Threshold) Append(dst []byte) []byte {
+	switch {
+	case t >= NeverSample:
+		return dst
+	case t == AlwaysSample:
+		return append(dst, '0')
+	}
+	dst = appendHex(dst, uint64(t))
+	for dst[len(dst)-1] == '0' {
+		dst = dst[:len(dst)-1]
+	}
+	return dst
+}
+
+func appendHex(dst []byte, v uint64) []byte {
+	const digits = "0123456789abcdef"
+	for shift := randomnessBits - 4; shift >= 0; shift -= 4 {
+		dst = append(dst, digits[v>>shift&0xf])
+	}
+	return dst
+}
+
+// ParseThreshold parses the tracestate "th" encoding of a threshold.
+func ParseThreshold(s string) (Threshold, error) {
+	if len(s) == 0 || len(s) > thresholdDigits {
+		return 0, ErrInvalidThreshold
+	}
+	v, err := strconv.ParseUint(s, 16, 64)
+	if err != nil {
+		return 0, ErrInvalidThreshold
+	}
+	return Threshold(v << (4 * (thresholdDigits - len(s)))), nil
+}
+
+// MarshalText implements encoding.TextMarshaler. It fails for NeverSample.
+func (t Threshold) MarshalText() ([]byte, error) {
+	if t >= NeverSample {
+		return nil, ErrInvalidThreshold
+	}
+	return t.Append(nil), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (t *Threshold) UnmarshalText(text []byte) error {
+	v, err := ParseThreshold(string(text))
+	if err != nil {
+		return err
+	}
+	*t = v
+	return nil
+}
+
+// Table holds the sampling thresholds of tenants, and a default threshold for
+// the others. Lookups are lock free and do not allocate; updates copy the
+// table, so it suits rates that change far less often than they are read.
+// Tables are created by NewTable.
+type Table struct {
+	mu    sync.Mutex // Serializes updates.
+	state atomic.Pointer[tableState]
+}
+
+type tableState struct {
+	def     Threshold
+	tenants map[string]Threshold
+}
+
+// NewTable returns a Table with no tenants and threshold def for all.
+func NewTable(def Threshold) *Table {
+	t := new(Table)
+	t.state.Store(&tableState{def: def})
+	return t
+}
+
+// Threshold returns the threshold of tenant.
+func (t *Table) Threshold(tenant string) Threshold {
+	s := t.state.Load()
+	if th, ok := s.tenants[tenant]; ok {
+		return th
+	}
+	return s.def
+}
+
+// Keep reports whether tenant keeps the item with ID id.
+func (t *Table) Keep(tenant string, id []byte) bool {
+	return t.Threshold(tenant).Keep(id)
+}
+
+// KeepString is the string version of Keep.
+func (t *Table) KeepString(tenant, id string) bool {
+	return t.Threshold(tenant).KeepString(id)
+}
+
+// Default returns the threshold of tenants without their own.
+func (t *Table) Default() Threshold {
+	return t.state.Load().def
+}
+
+// SetDefault sets the threshold of tenants without their own.
+func (t *Table) SetDefault(th Threshold) {
+	t.update(func(s *tableState) bool {
+		s.def = th
+		return true
+	})
+}
+
+// Set sets the threshold of tenant. Lowering a threshold only adds items to
+// those kept, but raising one drops items that were kept before; use Raise
+// where that must not happen.
+func (t *Table) Set(tenant string, th Threshold) {
+	t.update(func(s *tableState) bool {
+		s.tenants[tenant] = th
+		return true
+	})
+}
+
+// Raise raises the sampling rate of tenant to that of th, by lowering its
+// threshold to th, and reports whether it did. It ignores a th that would
+// lower the rate, so that an item once kept for tenant stays kept.
+func (t *Table) Raise(tenant string, th Threshold) bool {
+	return t.update(func(s *tableState) bool {
+		cur, ok := s.tenants[tenant]
+		if !ok {
+			cur = s.def
+		}
+		if th >= cur {
+			return false
+		}
+		s.tenants[tenant] = th
+		return true
+	})
+}
+
+// Delete removes the threshold of tenant, which reverts to the default.
+func (t *Table) Delete(tenant string) {
+	t.update(func(s *tableState) bool {
+		if _, ok := s.tenants[tenant]; !ok {
+			return false
+		}
+		delete(s.tenants, tenant)
+		return true
+	})
+}
+
+// update applies f to a copy of the state, and publishes the copy if f
+// reports a change.
+func (t *Table) update(f func(*tableState) bool) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	old := t.state.Load()
+	s := &tableState{def: old.def, tenants: make(map[string]Threshold, len(old.tenants)+1)}
+	for k, v := range old.tenants {
+		s.tenants[k] = v
+	}
+	if !f(s) {
+		return false
+	}
+	t.state.Store(s)
+	return true
+}
@@ -0,0 +1,194 @@
+package sample
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+
+	murmur3 "github.com/m3db/stackmurmur3/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThresholdEncoding(t *testing.T) {
+	cases := []struct {
+		p  float64
+		th string
+	}{
+		{1, "0"},
+		{0.5, "8"},
+		{0.25, "c"},
+		{0.1, "e6666666666666"},
+		{1.0 / 3, "aaaaaaaaaaaaac"},
+		{0.01, "fd70a3d70a3d71"},
+		{1.0 / (1 << 56), "ffffffffffffff"},
+		{1e-20, "ffffffffffffff"},
+	}
+	for _, c := range cases {
+		th, err := ThresholdFor(c.p)
+		require.NoError(t, err)
+		assert.Equal(t, c.th, th.String(), c.p)
+
+		parsed, err := ParseThreshold(c.th)
+		require.NoError(t, err)
+		assert.Equal(t, th, parsed)
+		if c.p >= 1.0/(1<<56) {
+			assert.InEpsilon(t, c.p, th.Probability(), 1e-9, c.p)
+		}
+	}
+
+	never, err := ThresholdFor(0)
+	require.NoError(t, err)
+	assert.Equal(t, NeverSample, never)
+	assert.Equal(t, "", never.String())
+	assert.Equal(t, 0.0, never.Probability())
+	_, err = never.MarshalText()
+	assert.Equal(t, ErrInvalidThreshold, err)
+
+	for _, p := range []float64{-0.1, 1.1} {
+		_, err := ThresholdFor(p)
+		assert.Equal(t, ErrInvalidThreshold, err, p)
+	}
+	for _, s := range []string{"", "g", "-1", "+1", "0x1", "fffffffffffffff"} {
+		_, err := ParseThreshold(s)
+		assert.Equal(t, ErrInvalidThreshold, err, s)
+	}
+
+	// Shorter encodings stand for their trailing zeros.
+	th, err := ParseThreshold("c00")
+	require.NoError(t, err)
+	assert.Equal(t, Threshold(0xc<<52), th)
+	assert.Equal(t, "c", th.String())
+
+	var u Threshold
+	require.NoError(t, u.UnmarshalText([]byte("e668")))
+	text, err := u.MarshalText()
+	require.NoError(t, err)
+	assert.Equal(t, "e668", string(text))
+}
+
+func TestRandomness(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		id := fmt.Sprintf("trace-%d", i)
+		r := Randomness([]byte(id))
+		assert.Equal(t, murmur3.Sum64([]byte(id))&(1<<56-1), r)
+		assert.Equal(t, r, StringRandomness(id))
+
+		rv := AppendRandomness(nil, r)
+		require.Len(t, rv, 14)
+		back, err := strconv.ParseUint(string(rv), 16, 64)
+		require.NoError(t, err)
+		assert.Equal(t, r, back)
+	}
+}
+
+func TestKeep(t *testing.T) {
+	const n = 20000
+	ids := make([]string, n)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("%032x", i)
+	}
+
+	var prev map[string]bool
+	for _, p := range []float64{0, 0.01, 0.1, 0.5, 0.9, 1} {
+		th, err := ThresholdFor(p)
+		require.NoError(t, err)
+		kept := make(map[string]bool)
+		for _, id := range ids {
+			k := th.KeepString(id)
+			assert.Equal(t, k, th.Keep([]byte(id)))
+			assert.Equal(t, k, th.KeepRandomness(StringRandomness(id)))
+			if k {
+				kept[id] = true
+			}
+		}
+		assert.InDelta(t, p*n, float64(len(kept)), 4*n/100+1, p)
+		// Raising the rate only adds items.
+		for id := range prev {
+			assert.True(t, kept[id], "%v dropped at %v", id, p)
+		}
+		prev = kept
+	}
+}
+
+func TestTable(t *testing.T) {
+	half, _ := ThresholdFor(0.5)
+	tenth, _ := ThresholdFor(0.1)
+	tbl := NewTable(half)
+	assert.Equal(t, half, tbl.Threshold("a"))
+	assert.Equal(t, half, tbl.Default())
+
+	tbl.Set("a", tenth)
+	assert.Equal(t, tenth, tbl.Threshold("a"))
+	assert.Equal(t, half, tbl.Threshold("b"))
+
+	// Raise only ever lowers a threshold.
+	assert.False(t, tbl.Raise("b", NeverSample))
+	assert.False(t, tbl.Raise("b", half))
+	assert.True(t, tbl.Raise("a", half))
+	assert.Equal(t, half, tbl.Threshold("a"))
+	assert.True(t, tbl.Raise("b", AlwaysSample))
+	assert.Equal(t, AlwaysSample, tbl.Threshold("b"))
+
+	tbl.SetDefault(NeverSample)
+	assert.Equal(t, NeverSample, tbl.Threshold("c"))
+	assert.False(t, tbl.KeepString("c", "x"))
+	assert.True(t, tbl.KeepString("b", "x"))
+	assert.True(t, tbl.Keep("b", []byte("x")))
+
+	tbl.Delete("b")
+	assert.Equal(t, NeverSample, tbl.Threshold("b"))
+}
+
+func TestTableConcurrent(t *testing.T) {
+	tbl := NewTable(NeverSample)
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				tenant := strconv.Itoa(i % 10)
+				tbl.Raise(tenant, Threshold(NeverSample-Threshold(i*g)))
+				tbl.KeepString(tenant, "x")
+			}
+		}(g)
+	}
+	wg.Wait()
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, NeverSample-Threshold(3*(190+i)), tbl.Threshold(strconv.Itoa(i)))
+	}
+}
+
+func TestZeroAlloc(t *testing.T) {
+	th, _ := ThresholdFor(0.25)
+	tbl := NewTable(th)
+	tbl.Set("tenant", th)
+	id := []byte("4bf92f3577b34da6a3ce929d0e0e4736")
+	sid := string(id)
+	tenant := []byte("tenant")
+	var dst []byte
+	allocs := testing.AllocsPerRun(100, func() {
+		th.Keep(id)
+		th.KeepString(sid)
+		tbl.Keep(string(tenant), id)
+		tbl.KeepString("tenant", sid)
+		dst = th.Append(dst[:0])
+		dst = AppendRandomness(dst, Randomness(id))
+	})
+	assert.Zero(t, allocs)
+}
+
+func BenchmarkTableKeep(b *testing.B) {
+	th, _ := ThresholdFor(0.1)
+	tbl := NewTable(NeverSample)
+	for i := 0; i < 100; i++ {
+		tbl.Set(fmt.Sprint("tenant", i), th)
+	}
+	id := []byte("4bf92f3577b34da6a3ce929d0e0e4736")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		tbl.Keep("tenant42", id)
+	}
+}
@@ -0,0 +1,249 @@
+// Package scala reproduces the hashes of Scala's
+// scala.util.hashing.MurmurHash3, and the hash codes they are built from, so
+// that Go services can match the hashCode of Scala collections and case
+// classes, for example to agree with the partitioning of a Spark
+// HashPartitioner.
+//
+// Scala hashes values rather than bytes: the functions below take the hash
+// codes (Scala's ##) of the elements, which StringHashCode, LongHash,
+// DoubleHash and BooleanHash compute for common element types; an Int is its
+// own hash code. The hashes are built from the x86_32 steps exported
+// by the murmur3 package as Mix32, MixLast32 and Finalize32.
+//
+// Scala 2.13 changed the hashes of sequences, which it computes so that they
+// agree with those of ranges, and of case classes, into which it mixes the
+// hash code of the class name. Functions that differ are methods of Version.
+package scala
+
+import (
+	"math"
+
+	murmur3 "github.com/m3db/stackmurmur3/v2"
+)
+
+// The seeds that Scala hashes its collections with.
+const (
+	ArraySeed   = int32(0x3c074a61)
+	StringSeed  = int32(-0x0835802e) // 0xf7ca7fd2
+	ProductSeed = int32(-0x35014542) // 0xcafebabe
+	SeqSeed     = int32(83007)       // "Seq".hashCode
+	SetSeed     = int32(83010)       // "Set".hashCode
+)
+
+func mix(h, k int32) int32      { return int32(murmur3.Mix32(uint32(h), uint32(k))) }
+func mixLast(h, k int32) int32  { return int32(murmur3.MixLast32(uint32(h), uint32(k))) }
+func finalize(h, n int32) int32 { return int32(murmur3.Finalize32(uint32(h), uint32(n))) }
+func avalanche(h int32) int32   { return finalize(h, 0) }
+
+// forEachUTF16 calls f with each UTF-16 code unit of s, as held by the Java
+// string of s.
+func forEachUTF16(s string, f func(u int32)) {
+	for _, r := range s {
+		if r < 0x10000 {
+			f(r)
+			continue
+		}
+		r -= 0x10000
+		f(0xd800 + r>>10)
+		f(0xdc00 + r&0x3ff)
+	}
+}
+
+// StringHash returns MurmurHash3.stringHash(s, seed), which mixes the UTF-16
+// code units of s in pairs.
+func StringHash(s string, seed int32) int32 {
+	h, n, prev := seed, int32(0), int32(0)
+	forEachUTF16(s, func(u int32) {
+		if n&1 == 1 {
+			h = mix(h, prev<<16+u)
+		}
+		prev = u
+		n++
+	})
+	if n&1 == 1 {
+		h = mixLast(h, prev)
+	}
+	return finalize(h, n)
+}
+
+// UnorderedHash returns MurmurHash3.unorderedHash of elements with hash
+// codes hashes, which does not depend on their order. Sets hash with SetSeed.
+func UnorderedHash(hashes []int32, seed int32) int32 {
+	var a, b, n int32
+	c := int32(1)
+	for _, x := range hashes {
+		a += x
+		b ^= x
+		c *= x | 1
+		n++
+	}
+	h := mix(seed, a)
+	h = mix(h, b)
+	h = mixLast(h, c)
+	return finalize(h, n)
+}
+
+// RangeHash returns MurmurHash3.rangeHash(start, step, last, seed), the hash
+// of a range of integers, which the 2.13 ordered hashes of integer sequences
+// in arithmetic progression agree with.
+func RangeHash(start, step, last, seed int32) int32 {
+	return avalanche(mix(mix(mix(seed, start), step), last))
+}
+
+// Version is a Scala version whose hashes differ from the others.
+type Version int
+
+const (
+	// Scala212 is Scala 2.12, and the earlier versions since 2.10.
+	Scala212 Version = 212
+	// Scala213 is Scala 2.13 and Scala 3.
+	Scala213 Version = 213
+)
+
+// OrderedHash returns MurmurHash3.orderedHash of elements with hash codes
+// hashes, in order. Sequences hash with SeqSeed.
+func (v Version) OrderedHash(hashes []int32, seed int32) int32 {
+	h := seed
+	if v < Scala213 || len(hashes) < 2 {
+		for _, x := range hashes {
+			h = mix(h, x)
+		}
+		return finalize(h, int32(len(hashes)))
+	}
+
+	// Scala 2.13 hashes an arithmetic progression like a range.
+	initial := hashes[0]
+	h = mix(h, initial)
+	h0 := h
+	prev := hashes[1]
+	rangeDiff := prev - initial
+	for i := 2; i < len(hashes); i++ {
+		h = mix(h, prev)
+		x := hashes[i]
+		if rangeDiff != x-prev || rangeDiff == 0 {
+			h = mix(h, x)
+			for _, x := range hashes[i+1:] {
+				h = mix(h, x)
+			}
+			return finalize(h, int32(len(hashes)))
+		}
+		prev = x
+	}
+	return avalanche(mix(mix(h0, rangeDiff), prev))
+}
+
+// ArrayHash returns MurmurHash3.arrayHash of an array whose elements have
+// hash codes hashes. Arrays hash with ArraySeed. It is the same function as
+// OrderedHash.
+func (v Version) ArrayHash(hashes []int32, seed int32) int32 {
+	return v.OrderedHash(hashes, seed)
+}
+
+// ProductHash returns MurmurHash3.productHash of a product, such as a case
+// class instance, with productPrefix prefix and elements with hash codes
+// elems. Scala 2.13 mixes in the hash code of the prefix first.
+func (v Version) ProductHash(prefix string, elems []int32, seed int32) int32 {
+	if len(elems) == 0 {
+		return StringHashCode(prefix)
+	}
+	h := seed
+	if v >= Scala213 {
+		h = mix(h, StringHashCode(prefix))
+	}
+	for _, x := range elems {
+		h = mix(h, x)
+	}
+	return finalize(h, int32(len(elems)))
+}
+
+// CaseClassHash returns the hashCode of an instance of the case class named
+// name, whose fields have hash codes fields: its ProductHash with
+// ProductSeed. A Spark HashPartitioner partitions by this hash.
+func (v Version) CaseClassHash(name string, fields []int32) int32 {
+	return v.ProductHash(name, fields, ProductSeed)
+}
+
+// StringHashCode returns the hash code of a string, Java's String.hashCode,
+// computed over the UTF-16 code units of s.
+func StringHashCode(s string) int32 {
+	h := int32(0)
+	forEachUTF16(s, func(u int32) { h = 31*h + u })
+	return h
+}
+
+// LongHash returns the Scala hash code of a Long: its value if it fits in an
+// Int, otherwise LongHashCode.
+func LongHash(v int64) int32 {
+	if iv := int32(v); int64(iv) == v {
+		return iv
+	}
+	return LongHashCode(v)
+}
+
+// LongHashCode returns the hash code of a Long, Java's Long.hashCode: the xor
+// of its two halves. It is the hash code of a top-level Long key; see
+// Partition.
+func LongHashCode(v int64) int32 {
+	return int32(v ^ int64(uint64(v)>>32))
+}
+
+// DoubleHash returns the Scala hash code of a Double, which agrees with the
+// hash codes of the Int, Long or Float of the same value.
+func DoubleHash(v float64) int32 {
+	if v == math.Trunc(v) {
+		if v >= math.MinInt32 && v <= math.MaxInt32 {
+			return int32(v)
+		}
+		if v >= math.MinInt64 && v < 1<<63 {
+			return LongHash(int64(v))
+		}
+		if v == 1<<63 {
+			// Java's conversion saturates 2^63 to the largest Long, which
+			// then converts back to 2^63.
+			return LongHash(math.MaxInt64)
+		}
+	}
+	if f := float32(v); float64(f) == v {
+		return int32(math.Float32bits(f))
+	}
+	return DoubleHashCode(v)
+}
+
+// DoubleHashCode returns the hash code of a Double, Java's Double.hashCode:
+// the xor of the two halves of its bits, with NaN canonical. It is the hash
+// code of a top-level Double key; see Partition.
+func DoubleHashCode(v float64) int32 {
+	bits := math.Float64bits(v)
+	if v != v {
+		bits = 0x7ff8000000000000
+	}
+	return int32(bits ^ bits>>32)
+}
+
+// BooleanHash returns the hash code of a Boolean: 1231 for true, 1237 for
+// false.
+func BooleanHash(v bool) int32 {
+	if v {
+		return 1231
+	}
+	return 1237
+}
+
+// Partition returns the partition a Spark HashPartitioner assigns a key with
+// hash code hash to: its non-negative remainder modulo numPartitions. Spark
+// SQL shuffles partition rows by a different hash.
+//
+// HashPartitioner uses the key's Java hashCode, not its Scala ##. The two
+// agree for strings, Ints, collections and case classes, but a top-level Long
+// or Double key is boxed and hashes with Java's Long.hashCode or
+// Double.hashCode: pass LongHashCode or DoubleHashCode, not LongHash or
+// DoubleHash. For example the key -1L has hash code 0, not -1. Long and
+// Double fields and elements inside a collection or case class do hash with
+// LongHash and DoubleHash.
+func Partition(hash int32, numPartitions int) int {
+	p := int(hash) % numPartitions
+	if p < 0 {
+		p += numPartitions
+	}
+	return p
+}
@@ -0,0 +1,211 @@
+package scala
+
+import (
+	"math"
+	"testing"
+	"unicode/utf16"
+
+	murmur3 "github.com/m3db/stackmurmur3/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// u32 converts the signed Scala seeds, which may be negative constants.
+func u32(x int32) uint32 { return uint32(x) }
+
+func TestSeeds(t *testing.T) {
+	assert.Equal(t, uint32(0xf7ca7fd2), u32(StringSeed))
+	assert.Equal(t, uint32(0xcafebabe), u32(ProductSeed))
+	assert.Equal(t, SeqSeed, StringHashCode("Seq"))
+	assert.Equal(t, SetSeed, StringHashCode("Set"))
+}
+
+func TestSparkSQLHash(t *testing.T) {
+	// Spark SQL's hash(0) and hash(1). Its Murmur3_x86_32.hashInt with seed
+	// 42 is finalizeHash(mix(42, x), 4), so these values from the JVM check
+	// the steps the hashes below are built from.
+	assert.Equal(t, int32(933211791), finalize(mix(42, 0), 4))
+	assert.Equal(t, int32(-559580957), finalize(mix(42, 1), 4))
+}
+
+func TestStringHashCode(t *testing.T) {
+	assert.Equal(t, int32(0), StringHashCode(""))
+	assert.Equal(t, int32(99162322), StringHashCode("hello"))
+	assert.Equal(t, int32(-505841268), StringHashCode("Hello, World"))
+	// U+1D11E is the surrogate pair d834 dd1e.
+	assert.Equal(t, int32(0xd834*31+0xdd1e), StringHashCode("𝄞"))
+}
+
+func TestStringHash(t *testing.T) {
+	for _, s := range []string{"", "a", "ab", "abc", "hello", "é😀x", "id-𝄞-1", "\xff"} {
+		// The reference mixes the code units in pairs, high one first.
+		units := utf16.Encode([]rune(s))
+		h := u32(StringSeed)
+		i := 0
+		for ; i+1 < len(units); i += 2 {
+			h = murmur3.Mix32(h, uint32(units[i])<<16|uint32(units[i+1]))
+		}
+		if i < len(units) {
+			h = murmur3.MixLast32(h, uint32(units[i]))
+		}
+		want := int32(murmur3.Finalize32(h, uint32(len(units))))
+		assert.Equal(t, want, StringHash(s, StringSeed), s)
+	}
+}
+
+func TestUnorderedHash(t *testing.T) {
+	a := []int32{1, -7, 42, 1 << 30, 0}
+	b := []int32{42, 0, 1 << 30, 1, -7}
+	assert.Equal(t, UnorderedHash(a, SetSeed), UnorderedHash(b, SetSeed))
+	assert.NotEqual(t, UnorderedHash(a, SetSeed), UnorderedHash(a[1:], SetSeed))
+	assert.NotEqual(t, UnorderedHash(a, SetSeed), UnorderedHash(a, SeqSeed))
+}
+
+func TestOrderedHash(t *testing.T) {
+	plain := func(hashes []int32, seed int32) int32 {
+		h := uint32(seed)
+		for _, x := range hashes {
+			h = murmur3.Mix32(h, uint32(x))
+		}
+		return int32(murmur3.Finalize32(h, uint32(len(hashes))))
+	}
+
+	for _, hashes := range [][]int32{
+		nil,
+		{5},
+		{1, 2},
+		{1, 2, 3},
+		{10, 7, 4, 1},
+		{1, 2, 4},
+		{3, 3, 3},
+		{1, 2, 3, 5, 6},
+	} {
+		assert.Equal(t, plain(hashes, SeqSeed), Scala212.OrderedHash(hashes, SeqSeed), hashes)
+		assert.Equal(t, Scala212.OrderedHash(hashes, ArraySeed), Scala212.ArrayHash(hashes, ArraySeed))
+		assert.Equal(t, Scala213.OrderedHash(hashes, ArraySeed), Scala213.ArrayHash(hashes, ArraySeed))
+	}
+
+	// Scala 2.13 hashes sequences of fewer than two elements, and those not
+	// in arithmetic progression, like 2.12.
+	for _, hashes := range [][]int32{nil, {5}, {1, 2, 4}, {3, 3, 3}, {1, 2, 3, 5, 6}} {
+		assert.Equal(t, plain(hashes, SeqSeed), Scala213.OrderedHash(hashes, SeqSeed), hashes)
+	}
+
+	// And arithmetic progressions like the ranges they equal.
+	assert.Equal(t, RangeHash(1, 1, 2, SeqSeed), Scala213.OrderedHash([]int32{1, 2}, SeqSeed))
+	assert.Equal(t, RangeHash(1, 1, 3, SeqSeed), Scala213.OrderedHash([]int32{1, 2, 3}, SeqSeed))
+	assert.Equal(t, RangeHash(10, -3, 1, SeqSeed), Scala213.OrderedHash([]int32{10, 7, 4, 1}, SeqSeed))
+	assert.NotEqual(t, plain([]int32{1, 2, 3}, SeqSeed), Scala213.OrderedHash([]int32{1, 2, 3}, SeqSeed))
+}
+
+func TestProductHash(t *testing.T) {
+	fields := []int32{StringHashCode("alice"), 42}
+
+	// Scala 2.12 ignores the prefix.
+	h := u32(ProductSeed)
+	for _, x := range fields {
+		h = murmur3.Mix32(h, uint32(x))
+	}
+	assert.Equal(t, int32(murmur3.Finalize32(h, 2)), Scala212.CaseClassHash("User", fields))
+	assert.Equal(t, Scala212.CaseClassHash("User", fields), Scala212.CaseClassHash("Account", fields))
+
+	// Scala 2.13 mixes it in first.
+	h = murmur3.Mix32(u32(ProductSeed), uint32(StringHashCode("User")))
+	for _, x := range fields {
+		h = murmur3.Mix32(h, uint32(x))
+	}
+	assert.Equal(t, int32(murmur3.Finalize32(h, 2)), Scala213.CaseClassHash("User", fields))
+	assert.NotEqual(t, Scala213.CaseClassHash("User", fields), Scala213.CaseClassHash("Account", fields))
+
+	// A product without elements hashes as its prefix: None.hashCode.
+	assert.Equal(t, int32(2433880), Scala212.CaseClassHash("None", nil))
+	assert.Equal(t, int32(2433880), Scala213.CaseClassHash("None", nil))
+}
+
+func TestLongHash(t *testing.T) {
+	assert.Equal(t, int32(0), LongHash(0))
+	assert.Equal(t, int32(-1), LongHash(-1))
+	assert.Equal(t, int32(math.MaxInt32), LongHash(math.MaxInt32))
+	assert.Equal(t, int32(math.MinInt32), LongHash(math.MinInt32))
+	// Beyond an Int, Long.hashCode: the xor of the two halves.
+	assert.Equal(t, int32(256), LongHash(1<<40))
+	assert.Equal(t, int32(1), LongHash(1<<32))
+	assert.Equal(t, int32(math.MinInt32), LongHash(math.MaxInt64))
+	assert.Equal(t, int32(math.MinInt32), LongHash(math.MinInt64))
+	assert.Equal(t, int32(math.MinInt32), LongHash(math.MinInt32-1))
+}
+
+func TestLongHashCode(t *testing.T) {
+	// A top-level Long key hashes with Java's Long.hashCode even when it
+	// fits in an Int.
+	assert.Equal(t, int32(0), LongHashCode(-1))
+	assert.Equal(t, int32(1), LongHashCode(1))
+	assert.Equal(t, int32(math.MaxInt32), LongHashCode(math.MinInt32))
+	assert.Equal(t, LongHash(1<<40), LongHashCode(1<<40))
+	assert.Equal(t, 0, Partition(LongHashCode(-1), 8))
+	assert.Equal(t, 7, Partition(LongHash(-1), 8))
+}
+
+func TestDoubleHashCode(t *testing.T) {
+	assert.Equal(t, int32(0x3ff00000), DoubleHashCode(1))
+	assert.Equal(t, int32(math.MinInt32), DoubleHashCode(math.Copysign(0, -1)))
+	assert.Equal(t, int32(0x7ff80000), DoubleHashCode(math.NaN()))
+	assert.Equal(t, DoubleHash(0.1), DoubleHashCode(0.1))
+}
+
+func TestDoubleHash(t *testing.T) {
+	// Whole numbers hash as the Int or Long of the same value.
+	assert.Equal(t, int32(1), DoubleHash(1))
+	assert.Equal(t, int32(-3), DoubleHash(-3))
+	assert.Equal(t, int32(0), DoubleHash(math.Copysign(0, -1)))
+	assert.Equal(t, LongHash(1<<40), DoubleHash(1<<40))
+	assert.Equal(t, LongHash(math.MinInt64), DoubleHash(math.MinInt64))
+	assert.Equal(t, LongHash(math.MaxInt64), DoubleHash(1<<63))
+
+	// Others that a Float holds hash as the Float.
+	assert.Equal(t, int32(0x3f000000), DoubleHash(0.5))
+	assert.Equal(t, int32(0x7f800000), DoubleHash(math.Inf(1)))
+	assert.Equal(t, int32(math.Float32bits(float32(math.Inf(-1)))), DoubleHash(math.Inf(-1)))
+	assert.Equal(t, int32(math.Float32bits(1e30)), DoubleHash(float64(float32(1e30))))
+
+	// The rest as Double.hashCode, with NaN canonical.
+	assert.Equal(t, int32(0x7ff80000), DoubleHash(math.NaN()))
+	assert.Equal(t, int32(0x7ff80000), DoubleHash(math.Float64frombits(0x7ff0000000000001)))
+	b := math.Float64bits(0.1)
+	assert.Equal(t, int32(b^b>>32), DoubleHash(0.1))
+	b = math.Float64bits(1e300)
+	assert.Equal(t, int32(b^b>>32), DoubleHash(1e300))
+}
+
+func TestBooleanHash(t *testing.T) {
+	assert.Equal(t, int32(1231), BooleanHash(true))
+	assert.Equal(t, int32(1237), BooleanHash(false))
+}
+
+func TestPartition(t *testing.T) {
+	assert.Equal(t, 0, Partition(0, 8))
+	assert.Equal(t, 3, Partition(11, 8))
+	assert.Equal(t, 5, Partition(-3, 8))
+	assert.Equal(t, 0, Partition(math.MinInt32, 8))
+	assert.Equal(t, 1, Partition(math.MinInt32, 3))
+	assert.Equal(t, 1, Partition(math.MaxInt32, 3))
+}
+
+func TestZeroAlloc(t *testing.T) {
+	hashes := []int32{1, 2, 3, 5}
+	allocs := testing.AllocsPerRun(100, func() {
+		StringHash("id-𝄞-1", StringSeed)
+		UnorderedHash(hashes, SetSeed)
+		Scala213.OrderedHash(hashes, SeqSeed)
+		Scala213.CaseClassHash("User", hashes)
+		StringHashCode("hello")
+	})
+	assert.Zero(t, allocs)
+}
+
+func BenchmarkCaseClassHash(b *testing.B) {
+	fields := []int32{StringHashCode("alice"), 42}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Scala213.CaseClassHash("User", fields)
+	}
+}
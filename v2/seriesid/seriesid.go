@@ -0,0 +1,167 @@
+// Package seriesid computes canonical 128 bit IDs of metric series, given as
+// a metric name and an unordered set of tags, so that every service derives
+// the same ID for the same series.
+//
+// The ID is Sum128 of the canonical encoding of the series. With uvarint
+// denoting an unsigned LEB128 varint, as written by encoding/binary, and tags
+// sorted by name in byte order, that encoding is:
+//
+//	uvarint(len(name)) name
+//	uvarint(len(tags))
+//	for each tag: uvarint(len(tag.Name)) tag.Name uvarint(len(tag.Value)) tag.Value
+//
+// Every field is length prefixed, so no choice of names or values can make
+// two different series encode alike. Tag names must be unique.
+package seriesid
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	murmur3 "github.com/m3db/stackmurmur3/v2"
+	"github.com/m3db/stackmurmur3/v2/stackmurmur3"
+)
+
+// ErrDuplicateTag is returned, wrapped with the offending name, when a tag
+// set holds the same tag name more than once.
+var ErrDuplicateTag = errors.New("seriesid: duplicate tag name")
+
+// Tag is a tag name and value. Neither is copied by this package.
+type Tag struct {
+	Name, Value []byte
+}
+
+// Sum returns the ID of the series with metric name and tags. It sorts tags
+// in place by name, and does not allocate unless it returns an error.
+func Sum(name []byte, tags []Tag) (murmur3.Uint128, error) {
+	if err := Sort(tags); err != nil {
+		return murmur3.Uint128{}, err
+	}
+	d := stackmurmur3.New128()
+	var buf [binary.MaxVarintLen64]byte
+	writeBytes := func(b []byte) {
+		d.Write(buf[:binary.PutUvarint(buf[:], uint64(len(b)))])
+		d.Write(b)
+	}
+	writeBytes(name)
+	d.Write(buf[:binary.PutUvarint(buf[:], uint64(len(tags)))])
+	for i := range tags {
+		writeBytes(tags[i].Name)
+		writeBytes(tags[i].Value)
+	}
+	return d.SumUint128(), nil
+}
+
+// AppendCanonical appends the canonical encoding of the series to dst, for
+// example to store it alongside the ID or to check another implementation.
+// Like Sum, it sorts tags in place.
+func AppendCanonical(dst, name []byte, tags []Tag) ([]byte, error) {
+	if err := Sort(tags); err != nil {
+		return dst, err
+	}
+	dst = appendBytes(dst, name)
+	dst = binary.AppendUvarint(dst, uint64(len(tags)))
+	for i := range tags {
+		dst = appendBytes(dst, tags[i].Name)
+		dst = appendBytes(dst, tags[i].Value)
+	}
+	return dst, nil
+}
+
+func appendBytes(dst, b []byte) []byte {
+	dst = binary.AppendUvarint(dst, uint64(len(b)))
+	return append(dst, b...)
+}
+
+// Sort sorts tags in place by name without allocating, and returns an error
+// wrapping ErrDuplicateTag if two tags have the same name.
+func Sort(tags []Tag) error {
+	if len(tags) <= 12 {
+		insertionSort(tags)
+	} else {
+		heapSort(tags)
+	}
+	for i := 1; i < len(tags); i++ {
+		if bytes.Equal(tags[i-1].Name, tags[i].Name) {
+			return fmt.Errorf("%w %q", ErrDuplicateTag, string(tags[i].Name))
+		}
+	}
+	return nil
+}
+
+func less(a, b *Tag) bool { return bytes.Compare(a.Name, b.Name) < 0 }
+
+func insertionSort(tags []Tag) {
+	for i := 1; i < len(tags); i++ {
+		for j := i; j > 0 && less(&tags[j], &tags[j-1]); j-- {
+			tags[j], tags[j-1] = tags[j-1], tags[j]
+		}
+	}
+}
+
+func heapSort(tags []Tag) {
+	for i := len(tags)/2 - 1; i >= 0; i-- {
+		siftDown(tags, i)
+	}
+	for end := len(tags) - 1; end > 0; end-- {
+		tags[0], tags[end] = tags[end], tags[0]
+		siftDown(tags[:end], 0)
+	}
+}
+
+func siftDown(tags []Tag, root int) {
+	for {
+		child := 2*root + 1
+		if child >= len(tags) {
+			return
+		}
+		if child+1 < len(tags) && less(&tags[child], &tags[child+1]) {
+			child++
+		}
+		if !less(&tags[root], &tags[child]) {
+			return
+		}
+		tags[root], tags[child] = tags[child], tags[root]
+		root = child
+	}
+}
+
+// Builder accumulates the name and tags of a series. It references, rather
+// than copies, the bytes it is given. The zero Builder is ready to use, and
+// reusing one through Reset avoids allocating once its tag slice has grown.
+type Builder struct {
+	name []byte
+	tags []Tag
+}
+
+// SetName sets the metric name.
+func (b *Builder) SetName(name []byte) { b.name = name }
+
+// Add adds a tag. Duplicate names are reported by ID and AppendCanonical.
+func (b *Builder) Add(name, value []byte) {
+	b.tags = append(b.tags, Tag{Name: name, Value: value})
+}
+
+// Len returns the number of tags added.
+func (b *Builder) Len() int { return len(b.tags) }
+
+// ID returns the ID of the series built so far.
+func (b *Builder) ID() (murmur3.Uint128, error) {
+	return Sum(b.name, b.tags)
+}
+
+// AppendCanonical appends the canonical encoding of the series built so far
+// to dst.
+func (b *Builder) AppendCanonical(dst []byte) ([]byte, error) {
+	return AppendCanonical(dst, b.name, b.tags)
+}
+
+// Reset clears the name and tags, keeping the tag slice for reuse.
+func (b *Builder) Reset() {
+	for i := range b.tags {
+		b.tags[i] = Tag{}
+	}
+	b.name, b.tags = nil, b.tags[:0]
+}
@@ -0,0 +1,155 @@
+package seriesid
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	murmur3 "github.com/m3db/stackmurmur3/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func tagsOf(kv ...string) []Tag {
+	var tags []Tag
+	for i := 0; i < len(kv); i += 2 {
+		tags = append(tags, Tag{Name: []byte(kv[i]), Value: []byte(kv[i+1])})
+	}
+	return tags
+}
+
+func TestGolden(t *testing.T) {
+	// These IDs are part of the format; they must never change.
+	id, err := Sum([]byte("cpu.load"), tagsOf("region", "us-east-1", "host", "a"))
+	require.NoError(t, err)
+	assert.Equal(t, "5b2ed0e3c43bed47dbaf0f4fd8cd2442", id.String())
+
+	enc, err := AppendCanonical(nil, []byte("cpu.load"), tagsOf("region", "us-east-1", "host", "a"))
+	require.NoError(t, err)
+	assert.Equal(t, "\x08cpu.load\x02\x04host\x01a\x06region\x09us-east-1", string(enc))
+	assert.Equal(t, murmur3.SumUint128(enc), id)
+
+	id, err = Sum(nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, murmur3.SumUint128([]byte{0, 0}), id)
+}
+
+func TestOrderIndependent(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, n := range []int{1, 2, 5, 12, 13, 40} {
+		tags := make([]Tag, n)
+		for i := range tags {
+			tags[i] = Tag{Name: []byte(fmt.Sprintf("tag%03d", i)), Value: []byte(fmt.Sprint(i * i))}
+		}
+		want, err := Sum([]byte("m"), tags)
+		require.NoError(t, err)
+		for i := 1; i < n; i++ {
+			assert.True(t, string(tags[i-1].Name) < string(tags[i].Name))
+		}
+		for k := 0; k < 10; k++ {
+			rnd.Shuffle(n, func(i, j int) { tags[i], tags[j] = tags[j], tags[i] })
+			got, err := Sum([]byte("m"), tags)
+			require.NoError(t, err)
+			assert.Equal(t, want, got)
+		}
+	}
+}
+
+func TestUnambiguous(t *testing.T) {
+	ids := make(map[murmur3.Uint128]string)
+	add := func(desc, name string, tags []Tag) {
+		id, err := Sum([]byte(name), tags)
+		require.NoError(t, err)
+		prev, dup := ids[id]
+		assert.False(t, dup, "%s collides with %s", desc, prev)
+		ids[id] = desc
+	}
+	add("a", "m", tagsOf("ab", "c"))
+	add("b", "m", tagsOf("a", "bc"))
+	add("c", "m", tagsOf("a", "b", "c", ""))
+	add("d", "m", tagsOf("a", "b"))
+	add("e", "ma", tagsOf("b", ""))
+	add("f", "m", tagsOf("ab", ""))
+	add("g", "m", nil)
+	add("h", "m", tagsOf("", ""))
+	add("i", "", tagsOf("m", ""))
+}
+
+func TestDuplicateTag(t *testing.T) {
+	for _, tags := range [][]Tag{
+		tagsOf("host", "a", "host", "b"),
+		tagsOf("host", "a", "region", "x", "host", "a"),
+	} {
+		_, err := Sum([]byte("m"), tags)
+		assert.True(t, errors.Is(err, ErrDuplicateTag))
+		assert.Contains(t, err.Error(), `"host"`)
+		_, err = AppendCanonical(nil, []byte("m"), tags)
+		assert.True(t, errors.Is(err, ErrDuplicateTag))
+	}
+
+	many := make([]Tag, 20)
+	for i := range many {
+		many[i] = Tag{Name: []byte(fmt.Sprint(i % 19))}
+	}
+	assert.True(t, errors.Is(Sort(many), ErrDuplicateTag))
+}
+
+func TestBuilder(t *testing.T) {
+	var b Builder
+	b.SetName([]byte("cpu.load"))
+	b.Add([]byte("region"), []byte("us-east-1"))
+	b.Add([]byte("host"), []byte("a"))
+	assert.Equal(t, 2, b.Len())
+	id, err := b.ID()
+	require.NoError(t, err)
+	want, _ := Sum([]byte("cpu.load"), tagsOf("host", "a", "region", "us-east-1"))
+	assert.Equal(t, want, id)
+	enc, err := b.AppendCanonical(nil)
+	require.NoError(t, err)
+	assert.Equal(t, murmur3.SumUint128(enc), id)
+
+	b.Add([]byte("host"), []byte("b"))
+	_, err = b.ID()
+	assert.True(t, errors.Is(err, ErrDuplicateTag))
+
+	b.Reset()
+	assert.Equal(t, 0, b.Len())
+	id, err = b.ID()
+	require.NoError(t, err)
+	want, _ = Sum(nil, nil)
+	assert.Equal(t, want, id)
+}
+
+func TestZeroAlloc(t *testing.T) {
+	name := []byte("cpu.load")
+	kv := tagsOf("region", "us-east-1", "host", "a", "dc", "x", "env", "prod")
+	tags := make([]Tag, 0, 20)
+	var b Builder
+	allocs := testing.AllocsPerRun(100, func() {
+		tags = append(tags[:0], kv...)
+		if _, err := Sum(name, tags); err != nil {
+			t.Fatal(err)
+		}
+		b.Reset()
+		b.SetName(name)
+		for _, tag := range kv {
+			b.Add(tag.Name, tag.Value)
+		}
+		if _, err := b.ID(); err != nil {
+			t.Fatal(err)
+		}
+	})
+	assert.Zero(t, allocs)
+}
+
+func BenchmarkSum(b *testing.B) {
+	name := []byte("http.requests")
+	kv := tagsOf("service", "api", "method", "GET", "status", "200", "region", "us-east-1", "host", "a-1234")
+	tags := make([]Tag, len(kv))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		copy(tags, kv)
+		Sum(name, tags)
+	}
+}
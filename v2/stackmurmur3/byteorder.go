@@ -0,0 +1,44 @@
+package stackmurmur3
+
+import (
+	"encoding/binary"
+
+	murmur3 "github.com/m3db/stackmurmur3/v2"
+)
+
+// Make sure interfaces are correctly implemented.
+var (
+	_ murmur3.ByteOrderSummer = Digest32{}
+	_ murmur3.ByteOrderSummer = Digest64{}
+	_ murmur3.ByteOrderSummer = Digest128{}
+	_ murmur3.Uint128Summer   = Digest128{}
+)
+
+// SumBE appends the big endian sum to b. It is the same as Sum.
+func (d Digest32) SumBE(b []byte) []byte { return d.Sum(b) }
+
+// SumLE appends the little endian sum to b, the byte order of the C
+// reference output.
+func (d Digest32) SumLE(b []byte) []byte {
+	return binary.LittleEndian.AppendUint32(b, d.Sum32())
+}
+
+// SumBE appends the big endian sum to b. It is the same as Sum.
+func (d Digest64) SumBE(b []byte) []byte { return d.Sum(b) }
+
+// SumLE appends the little endian sum to b, the byte order of the first half
+// of the C reference output.
+func (d Digest64) SumLE(b []byte) []byte {
+	return binary.LittleEndian.AppendUint64(b, d.Sum64())
+}
+
+// SumBE appends the big endian sum, h1 then h2, to b. It is the same as Sum.
+func (d Digest128) SumBE(b []byte) []byte { return d.Sum(b) }
+
+// SumLE appends h1 then h2 to b, each little endian, the byte order of the C
+// reference output.
+func (d Digest128) SumLE(b []byte) []byte {
+	h1, h2 := d.Sum128()
+	b = binary.LittleEndian.AppendUint64(b, h1)
+	return binary.LittleEndian.AppendUint64(b, h2)
+}
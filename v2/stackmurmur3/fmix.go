@@ -0,0 +1,89 @@
+package stackmurmur3
+
+import murmur3 "github.com/m3db/stackmurmur3/v2"
+
+// Fmix32 is the murmur3 32 bit finalizer, murmur3.Finalize32(h, 0). It is a
+// bijection with full avalanche: every input bit affects every output bit.
+// Unfmix32 inverts it.
+func Fmix32(h uint32) uint32 { return murmur3.Finalize32(h, 0) }
+
+// Unfmix32 returns the x for which Fmix32(x) == h.
+func Unfmix32(h uint32) uint32 {
+	h ^= h >> 16
+	h *= 0x7ed1b41d // Inverse of 0xc2b2ae35 mod 2^32.
+	h ^= h>>13 ^ h>>26
+	h *= 0xa5cb9243 // Inverse of 0x85ebca6b mod 2^32.
+	h ^= h >> 16
+	return h
+}
+
+// Fmix64 is the murmur3 64 bit finalizer. It is a bijection with full
+// avalanche: every input bit affects every output bit. Unfmix64 inverts it.
+func Fmix64(k uint64) uint64 {
+	k ^= k >> 33
+	k *= 0xff51afd7ed558ccd
+	k ^= k >> 33
+	k *= 0xc4ceb9fe1a85ec53
+	k ^= k >> 33
+	return k
+}
+
+// Unfmix64 returns the x for which Fmix64(x) == k.
+func Unfmix64(k uint64) uint64 {
+	k ^= k >> 33
+	k *= 0x9cb4b2f8129337db // Inverse of 0xc4ceb9fe1a85ec53 mod 2^64.
+	k ^= k >> 33
+	k *= 0x4f74430c22a54005 // Inverse of 0xff51afd7ed558ccd mod 2^64.
+	k ^= k >> 33
+	return k
+}
+
+// Mixer32 is a seeded bijection on uint32, for example to scramble
+// sequential IDs into well distributed keys that can be turned back into the
+// IDs. It whitens the input with a key derived from the seed, applies
+// Fmix32, whitens again with a second key and applies Fmix32 once more.
+// Fmix32 maps 0 to 0; the whitening keys prevent such fixed points from
+// surviving for a given seed.
+//
+// This is not encryption: anyone who knows the seed, or enough pairs of
+// inputs and outputs, can invert it.
+type Mixer32 struct {
+	k1, k2 uint32
+}
+
+// NewMixer32 returns the Mixer32 for seed.
+func NewMixer32(seed uint32) Mixer32 {
+	k1 := Fmix32(seed + 0x9e3779b9)
+	return Mixer32{k1: k1, k2: Fmix32(k1 + 0x9e3779b9)}
+}
+
+// Mix returns the image of x.
+func (m Mixer32) Mix(x uint32) uint32 {
+	return Fmix32(Fmix32(x^m.k1) ^ m.k2)
+}
+
+// Unmix returns the x for which m.Mix(x) == y.
+func (m Mixer32) Unmix(y uint32) uint32 {
+	return Unfmix32(Unfmix32(y)^m.k2) ^ m.k1
+}
+
+// Mixer64 is the 64 bit version of Mixer32.
+type Mixer64 struct {
+	k1, k2 uint64
+}
+
+// NewMixer64 returns the Mixer64 for seed.
+func NewMixer64(seed uint64) Mixer64 {
+	k1 := Fmix64(seed + 0x9e3779b97f4a7c15)
+	return Mixer64{k1: k1, k2: Fmix64(k1 + 0x9e3779b97f4a7c15)}
+}
+
+// Mix returns the image of x.
+func (m Mixer64) Mix(x uint64) uint64 {
+	return Fmix64(Fmix64(x^m.k1) ^ m.k2)
+}
+
+// Unmix returns the x for which m.Mix(x) == y.
+func (m Mixer64) Unmix(y uint64) uint64 {
+	return Unfmix64(Unfmix64(y)^m.k2) ^ m.k1
+}
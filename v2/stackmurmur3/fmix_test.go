@@ -0,0 +1,67 @@
+package stackmurmur3
+
+import (
+	"math"
+	"testing"
+	"testing/quick"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnfmix32Exhaustive(t *testing.T) {
+	if testing.Short() {
+		t.Skip("exhaustive 32 bit round trip skipped in short mode")
+	}
+	for x := uint64(0); x <= math.MaxUint32; x++ {
+		if v := Unfmix32(Fmix32(uint32(x))); v != uint32(x) {
+			t.Fatalf("Unfmix32(Fmix32(0x%x)) = 0x%x", x, v)
+		}
+	}
+}
+
+func TestUnfmix32(t *testing.T) {
+	f := func(x uint32) bool {
+		return Unfmix32(Fmix32(x)) == x && Fmix32(Unfmix32(x)) == x
+	}
+	require.NoError(t, quick.Check(f, quickcheckConfig))
+}
+
+func TestUnfmix64(t *testing.T) {
+	f := func(x uint64) bool {
+		return Unfmix64(Fmix64(x)) == x && Fmix64(Unfmix64(x)) == x
+	}
+	require.NoError(t, quick.Check(f, quickcheckConfig))
+	for _, x := range []uint64{0, 1, math.MaxUint64, 1 << 63, 1 << 33} {
+		assert.Equal(t, x, Unfmix64(Fmix64(x)))
+	}
+}
+
+func TestMixers(t *testing.T) {
+	f := func(seed32, x32 uint32, seed64, x64 uint64) bool {
+		m32, m64 := NewMixer32(seed32), NewMixer64(seed64)
+		return m32.Unmix(m32.Mix(x32)) == x32 && m32.Mix(m32.Unmix(x32)) == x32 &&
+			m64.Unmix(m64.Mix(x64)) == x64 && m64.Mix(m64.Unmix(x64)) == x64
+	}
+	require.NoError(t, quick.Check(f, quickcheckConfig))
+
+	// Sequential IDs spread out and differ between seeds.
+	a, b := NewMixer64(1), NewMixer64(2)
+	seen := make(map[uint64]bool)
+	for id := uint64(0); id < 1000; id++ {
+		seen[a.Mix(id)>>54] = true
+		assert.NotEqual(t, a.Mix(id), b.Mix(id))
+	}
+	assert.Greater(t, len(seen), 600)
+	assert.NotZero(t, NewMixer32(0).Mix(0))
+	assert.NotZero(t, NewMixer64(0).Mix(0))
+}
+
+func BenchmarkMixer64(b *testing.B) {
+	m := NewMixer64(42)
+	var x uint64
+	for i := 0; i < b.N; i++ {
+		x = m.Mix(x)
+	}
+	DoNotOptimize128[0] = x
+}
@@ -0,0 +1,140 @@
+package stackmurmur3
+
+import (
+	"fmt"
+	"io"
+
+	murmur3 "github.com/m3db/stackmurmur3/v2"
+)
+
+// SumMismatchError is returned when data that passed through a HashingReader
+// or HashingWriter does not hash to the expected sum.
+type SumMismatchError struct {
+	Want, Got murmur3.Uint128
+	N         int64 // Number of bytes hashed.
+}
+
+func (e *SumMismatchError) Error() string {
+	return fmt.Sprintf("stackmurmur3: sum mismatch after %d bytes: got %v, want %v", e.N, e.Got, e.Want)
+}
+
+// hashing is the state shared by HashingReader and HashingWriter.
+type hashing struct {
+	d      Digest128
+	n      int64
+	want   murmur3.Uint128
+	expect bool
+}
+
+func (h *hashing) write(p []byte) {
+	h.d.Write(p)
+	h.n += int64(len(p))
+}
+
+// Sum128 returns the sum of the bytes that passed through so far.
+func (h *hashing) Sum128() (h1, h2 uint64) { return h.d.Sum128() }
+
+// SumUint128 returns the sum of the bytes that passed through so far.
+func (h *hashing) SumUint128() murmur3.Uint128 { return h.d.SumUint128() }
+
+// Sum64 returns the 64 bit sum, h1, of the bytes that passed through so far.
+func (h *hashing) Sum64() uint64 { return Digest64(h.d).Sum64() }
+
+// N returns the number of bytes that passed through so far.
+func (h *hashing) N() int64 { return h.n }
+
+// Verify returns a *SumMismatchError if the bytes that passed through so far
+// do not hash to want.
+func (h *hashing) Verify(want murmur3.Uint128) error {
+	if got := h.d.SumUint128(); got != want {
+		return &SumMismatchError{Want: want, Got: got, N: h.n}
+	}
+	return nil
+}
+
+func (h *hashing) verifyExpected() error {
+	if !h.expect {
+		return nil
+	}
+	return h.Verify(h.want)
+}
+
+// HashingReader hashes everything read through it, so that a download can be
+// checksummed without a second pass.
+type HashingReader struct {
+	hashing
+	r io.Reader
+}
+
+// NewHashingReaderWithSeed returns a HashingReader reading from r, hashing
+// with a Digest128 initialized to seed1 and seed2.
+func NewHashingReaderWithSeed(r io.Reader, seed1, seed2 uint64) *HashingReader {
+	return &HashingReader{hashing: hashing{d: *New128WithSeed(seed1, seed2)}, r: r}
+}
+
+// NewHashingReader returns a HashingReader reading from r.
+func NewHashingReader(r io.Reader) *HashingReader {
+	return NewHashingReaderWithSeed(r, 0, 0)
+}
+
+// Expect makes Read return a *SumMismatchError instead of io.EOF if the data
+// read does not hash to want. It returns h.
+func (h *HashingReader) Expect(want murmur3.Uint128) *HashingReader {
+	h.want, h.expect = want, true
+	return h
+}
+
+// Read reads from the underlying reader and hashes the bytes read.
+func (h *HashingReader) Read(p []byte) (int, error) {
+	n, err := h.r.Read(p)
+	h.write(p[:n])
+	if err == io.EOF {
+		if verr := h.verifyExpected(); verr != nil {
+			err = verr
+		}
+	}
+	return n, err
+}
+
+// HashingWriter hashes everything written through it, so that an upload can
+// be checksummed as it is sent.
+type HashingWriter struct {
+	hashing
+	w io.Writer
+}
+
+// NewHashingWriterWithSeed returns a HashingWriter writing to w, hashing with
+// a Digest128 initialized to seed1 and seed2.
+func NewHashingWriterWithSeed(w io.Writer, seed1, seed2 uint64) *HashingWriter {
+	return &HashingWriter{hashing: hashing{d: *New128WithSeed(seed1, seed2)}, w: w}
+}
+
+// NewHashingWriter returns a HashingWriter writing to w.
+func NewHashingWriter(w io.Writer) *HashingWriter {
+	return NewHashingWriterWithSeed(w, 0, 0)
+}
+
+// Expect makes Close return a *SumMismatchError if the data written does not
+// hash to want. It returns h.
+func (h *HashingWriter) Expect(want murmur3.Uint128) *HashingWriter {
+	h.want, h.expect = want, true
+	return h
+}
+
+// Write writes p to the underlying writer and hashes the bytes written.
+func (h *HashingWriter) Write(p []byte) (int, error) {
+	n, err := h.w.Write(p)
+	h.write(p[:n])
+	return n, err
+}
+
+// Close closes the underlying writer if it is an io.Closer, then checks the
+// sum set by Expect. An error from the underlying Close takes precedence.
+func (h *HashingWriter) Close() error {
+	if c, ok := h.w.(io.Closer); ok {
+		if err := c.Close(); err != nil {
+			return err
+		}
+	}
+	return h.verifyExpected()
+}
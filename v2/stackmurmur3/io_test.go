@@ -0,0 +1,99 @@
+package stackmurmur3
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+
+	murmur3 "github.com/m3db/stackmurmur3/v2"
+	"github.com/m3db/stackmurmur3/v2/testdata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashingReader(t *testing.T) {
+	data := testdata.RandBytes(1000)
+	want := murmur3.SeedSumUint128(1, 2, data)
+
+	r := NewHashingReaderWithSeed(iotest.OneByteReader(bytes.NewReader(data)), 1, 2)
+	got, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, data, got)
+	assert.Equal(t, want, r.SumUint128())
+	assert.Equal(t, want.Hi, r.Sum64())
+	assert.Equal(t, int64(len(data)), r.N())
+	assert.NoError(t, r.Verify(want))
+
+	r = NewHashingReaderWithSeed(bytes.NewReader(data), 1, 2).Expect(want)
+	_, err = io.ReadAll(r)
+	assert.NoError(t, err)
+
+	r = NewHashingReader(bytes.NewReader(data)).Expect(want)
+	_, err = io.ReadAll(r)
+	var mismatch *SumMismatchError
+	require.True(t, errors.As(err, &mismatch), "%v", err)
+	assert.Equal(t, want, mismatch.Want)
+	assert.Equal(t, murmur3.SumUint128(data), mismatch.Got)
+	assert.Equal(t, int64(len(data)), mismatch.N)
+	assert.Contains(t, err.Error(), "sum mismatch after 1000 bytes")
+
+	// Errors other than io.EOF pass through without verification.
+	r = NewHashingReader(iotest.TimeoutReader(bytes.NewReader(data))).Expect(want)
+	_, err = io.ReadAll(r)
+	assert.Equal(t, iotest.ErrTimeout, err)
+}
+
+type closeRecorder struct {
+	bytes.Buffer
+	closed bool
+	err    error
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return c.err
+}
+
+func TestHashingWriter(t *testing.T) {
+	data := testdata.RandBytes(1000)
+	want := murmur3.SumUint128(data)
+
+	var buf closeRecorder
+	w := NewHashingWriter(&buf).Expect(want)
+	_, err := io.Copy(w, iotest.HalfReader(bytes.NewReader(data)))
+	require.NoError(t, err)
+	assert.Equal(t, data, buf.Bytes())
+	h1, h2 := w.Sum128()
+	assert.Equal(t, want, murmur3.Uint128{Hi: h1, Lo: h2})
+	assert.NoError(t, w.Close())
+	assert.True(t, buf.closed)
+
+	w = NewHashingWriterWithSeed(io.Discard, 3, 3).Expect(want)
+	w.Write(data)
+	var mismatch *SumMismatchError
+	require.True(t, errors.As(w.Close(), &mismatch))
+	assert.Equal(t, murmur3.SeedSumUint128(3, 3, data), mismatch.Got)
+
+	closeErr := errors.New("close failed")
+	w = NewHashingWriter(&closeRecorder{err: closeErr}).Expect(want)
+	assert.Equal(t, closeErr, w.Close())
+}
+
+type shortWriter struct{ n int }
+
+func (s *shortWriter) Write(p []byte) (int, error) {
+	if len(p) > s.n {
+		return s.n, io.ErrShortWrite
+	}
+	return len(p), nil
+}
+
+func TestHashingWriterShortWrite(t *testing.T) {
+	w := NewHashingWriter(&shortWriter{n: 3})
+	n, err := w.Write([]byte("hello"))
+	assert.Equal(t, 3, n)
+	assert.Equal(t, io.ErrShortWrite, err)
+	assert.Equal(t, murmur3.SumUint128([]byte("hel")), w.SumUint128())
+}
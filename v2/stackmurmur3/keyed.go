@@ -0,0 +1,117 @@
+package stackmurmur3
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"sync"
+
+	murmur3 "github.com/m3db/stackmurmur3/v2"
+)
+
+// Keyed hashes with a random key, so that hash values, and therefore
+// bucket and shard placement, differ between processes or tables.
+//
+// A secret key does not protect against hash flooding. MurmurHash3 has
+// multicollisions that do not depend on the seed: inputs can be crafted
+// offline that collide under every key, so an attacker can still fill one
+// bucket of a table indexed by untrusted input. Use a keyed hash designed
+// for this, such as hash/maphash or SipHash, for tables exposed to untrusted
+// keys. Nor is murmur3 a keyed PRF or a substitute for a MAC.
+//
+// All sums and digests from one Keyed agree with each other; sums from
+// different keys are unrelated. A Keyed is a small value that is safe to copy
+// and to use concurrently.
+type Keyed struct {
+	seed1 uint64
+	seed2 uint64
+}
+
+var (
+	processKeyOnce sync.Once
+	processKey     Keyed
+)
+
+// NewKeyed returns a Keyed with a fresh key drawn from crypto/rand, for
+// example one per hash table. It panics if the system random source fails.
+func NewKeyed() Keyed {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	return Keyed{
+		seed1: binary.LittleEndian.Uint64(b[0:]),
+		seed2: binary.LittleEndian.Uint64(b[8:]),
+	}
+}
+
+// ProcessKeyed returns a Keyed whose key is drawn from crypto/rand once and
+// shared for the lifetime of the process.
+func ProcessKeyed() Keyed {
+	processKeyOnce.Do(func() { processKey = NewKeyed() })
+	return processKey
+}
+
+// NewKeyedWithSeed returns a Keyed with a fixed key, so that hashes can be
+// reproduced, e.g. in tests. Do not use it for untrusted input.
+func NewKeyedWithSeed(seed1, seed2 uint64) Keyed {
+	return Keyed{seed1: seed1, seed2: seed2}
+}
+
+// Seeds returns the key, for example to persist it alongside hashes that must
+// be recomputed later with NewKeyedWithSeed. Treat it as a secret: anyone who
+// learns it can predict bucket and shard placement.
+func (k Keyed) Seeds() (seed1, seed2 uint64) {
+	return k.seed1, k.seed2
+}
+
+// seed32 derives the 32 bit seed for the x86_32 variant from the key.
+func (k Keyed) seed32() uint32 {
+	return uint32(k.seed1) ^ uint32(k.seed2>>32)
+}
+
+// Sum32 returns the keyed 32 bit sum of data.
+func (k Keyed) Sum32(data []byte) uint32 {
+	return murmur3.SeedSum32(k.seed32(), data)
+}
+
+// StringSum32 is the string version of Sum32.
+func (k Keyed) StringSum32(data string) uint32 {
+	return murmur3.SeedStringSum32(k.seed32(), data)
+}
+
+// Sum64 returns the keyed 64 bit sum of data.
+func (k Keyed) Sum64(data []byte) uint64 {
+	h1, _ := murmur3.SeedSum128(k.seed1, k.seed2, data)
+	return h1
+}
+
+// StringSum64 is the string version of Sum64.
+func (k Keyed) StringSum64(data string) uint64 {
+	h1, _ := murmur3.SeedStringSum128(k.seed1, k.seed2, data)
+	return h1
+}
+
+// Sum128 returns the keyed 128 bit sum of data.
+func (k Keyed) Sum128(data []byte) (h1, h2 uint64) {
+	return murmur3.SeedSum128(k.seed1, k.seed2, data)
+}
+
+// StringSum128 is the string version of Sum128.
+func (k Keyed) StringSum128(data string) (h1, h2 uint64) {
+	return murmur3.SeedStringSum128(k.seed1, k.seed2, data)
+}
+
+// New32 returns a Digest32 bound to the key; its Sum32 matches k.Sum32.
+func (k Keyed) New32() *Digest32 {
+	return New32WithSeed(k.seed32())
+}
+
+// New64 returns a Digest64 bound to the key; its Sum64 matches k.Sum64.
+func (k Keyed) New64() *Digest64 {
+	return (*Digest64)(New128WithSeed(k.seed1, k.seed2))
+}
+
+// New128 returns a Digest128 bound to the key; its Sum128 matches k.Sum128.
+func (k Keyed) New128() *Digest128 {
+	return New128WithSeed(k.seed1, k.seed2)
+}
@@ -0,0 +1,58 @@
+package stackmurmur3
+
+import (
+	"testing"
+
+	murmur3 "github.com/m3db/stackmurmur3/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyedDigestsAgree(t *testing.T) {
+	k := NewKeyed()
+	for _, elem := range []string{"", "a", "user-agent: curl", "The quick brown fox jumps over the lazy dog."} {
+		data := []byte(elem)
+
+		d32 := k.New32()
+		d32.Write(data)
+		assert.Equal(t, k.Sum32(data), d32.Sum32())
+		assert.Equal(t, k.Sum32(data), k.StringSum32(elem))
+
+		d64 := k.New64()
+		d64.Write(data)
+		assert.Equal(t, k.Sum64(data), d64.Sum64())
+		assert.Equal(t, k.Sum64(data), k.StringSum64(elem))
+
+		d128 := k.New128()
+		d128.Write(data)
+		h1, h2 := k.Sum128(data)
+		d1, d2 := d128.Sum128()
+		s1, s2 := k.StringSum128(elem)
+		assert.Equal(t, [2]uint64{h1, h2}, [2]uint64{d1, d2})
+		assert.Equal(t, [2]uint64{h1, h2}, [2]uint64{s1, s2})
+		assert.Equal(t, h1, k.Sum64(data))
+	}
+}
+
+func TestKeyedRandom(t *testing.T) {
+	data := []byte("metric_name")
+	a, b := NewKeyed(), NewKeyed()
+	assert.NotEqual(t, a, b)
+	assert.NotEqual(t, a.Sum64(data), b.Sum64(data))
+	assert.NotEqual(t, murmur3.Sum64(data), a.Sum64(data))
+
+	assert.Equal(t, ProcessKeyed(), ProcessKeyed())
+}
+
+func TestKeyedWithSeed(t *testing.T) {
+	data := []byte("hello")
+	k := NewKeyedWithSeed(1, 2)
+	s1, s2 := k.Seeds()
+	assert.Equal(t, uint64(1), s1)
+	assert.Equal(t, uint64(2), s2)
+
+	w1, w2 := murmur3.SeedSum128(1, 2, data)
+	h1, h2 := k.Sum128(data)
+	assert.Equal(t, w1, h1)
+	assert.Equal(t, w2, h2)
+	assert.Equal(t, murmur3.SeedSum32(1, data), k.Sum32(data))
+}
@@ -2,6 +2,8 @@ package stackmurmur3
 
 import (
 	"math/bits"
+
+	murmur3 "github.com/m3db/stackmurmur3/v2"
 )
 
 const (
@@ -174,8 +176,8 @@ func (d Digest128) Sum128() (h1, h2 uint64) {
 	h1 += h2
 	h2 += h1
 
-	h1 = fmix64(h1)
-	h2 = fmix64(h2)
+	h1 = Fmix64(h1)
+	h2 = Fmix64(h2)
 
 	h1 += h2
 	h2 += h1
@@ -183,11 +185,8 @@ func (d Digest128) Sum128() (h1, h2 uint64) {
 	return h1, h2
 }
 
-func fmix64(k uint64) uint64 {
-	k ^= k >> 33
-	k *= 0xff51afd7ed558ccd
-	k ^= k >> 33
-	k *= 0xc4ceb9fe1a85ec53
-	k ^= k >> 33
-	return k
+// SumUint128 finalizes the hash as a murmur3.Uint128.
+func (d Digest128) SumUint128() murmur3.Uint128 {
+	h1, h2 := d.Sum128()
+	return murmur3.Uint128{Hi: h1, Lo: h2}
 }
@@ -105,11 +105,5 @@ func (d Digest32) Sum32() (h1 uint32) {
 
 	h1 ^= uint32(d.clen)
 
-	h1 ^= h1 >> 16
-	h1 *= 0x85ebca6b
-	h1 ^= h1 >> 13
-	h1 *= 0xc2b2ae35
-	h1 ^= h1 >> 16
-
-	return h1
+	return Fmix32(h1)
 }
@@ -46,7 +46,7 @@ func TestQuickSum32(t *testing.T) {
 		goh1 := murmur3.Sum32(data)
 		goh2 := murmur3.StringSum32(string(data))
 		cpph1 := goh1
-		if testdata.IsLittleEndian {
+		if testdata.HasCReference {
 			cpph1 = testdata.SeedSum32(0, data)
 		}
 		return goh1 == goh2 && goh1 == cpph1
@@ -72,7 +72,7 @@ func TestQuickSeedSum32(t *testing.T) {
 			return binary.BigEndian.Uint32(h.Sum(nil))
 		}()
 		cpph1 := goh1
-		if testdata.IsLittleEndian {
+		if testdata.HasCReference {
 			cpph1 = testdata.SeedSum32(seed, data)
 		}
 		return goh1 == goh2 && goh1 == goh3 && goh1 == cpph1
@@ -87,7 +87,7 @@ func TestQuickSum64(t *testing.T) {
 		goh1 := murmur3.Sum64(data)
 		goh2 := murmur3.StringSum64(string(data))
 		cpph1 := goh1
-		if testdata.IsLittleEndian {
+		if testdata.HasCReference {
 			cpph1 = testdata.SeedSum64(0, data)
 		}
 		return goh1 == goh2 && goh1 == cpph1
@@ -107,7 +107,7 @@ func TestQuickSeedSum64(t *testing.T) {
 			return binary.BigEndian.Uint64(h.Sum(nil))
 		}()
 		cpph1 := goh1
-		if testdata.IsLittleEndian {
+		if testdata.HasCReference {
 			cpph1 = testdata.SeedSum64(seed, data)
 		}
 		return goh1 == goh2 && goh1 == goh3 && goh1 == cpph1
@@ -122,7 +122,7 @@ func TestQuickSum128(t *testing.T) {
 		goh1, goh2 := murmur3.Sum128(data)
 		goh3, goh4 := murmur3.StringSum128(string(data))
 		cpph1, cpph2 := goh1, goh2
-		if testdata.IsLittleEndian {
+		if testdata.HasCReference {
 			cpph1, cpph2 = testdata.SeedSum128(0, data)
 		}
 		return goh1 == goh3 && goh2 == goh4 && goh1 == cpph1 && goh2 == cpph2
@@ -149,8 +149,8 @@ func TestQuickSeedSum128(t *testing.T) {
 			return binary.BigEndian.Uint64(sum), binary.BigEndian.Uint64(sum[8:])
 		}()
 		cpph1, cpph2 := goh1, goh2
-		if testdata.IsLittleEndian {
-			testdata.SeedSum128(seed, data)
+		if testdata.HasCReference {
+			cpph1, cpph2 = testdata.SeedSum128(seed, data)
 		}
 		return goh1 == goh3 && goh2 == goh4 &&
 			goh1 == goh5 && goh2 == goh6 &&
@@ -353,3 +353,41 @@ func TestDigest128ZeroAllocWrite(t *testing.T) {
 	endAllocs := stats.Mallocs
 	assert.Equal(t, startAllocs, endAllocs)
 }
+
+func TestSumUint128(t *testing.T) {
+	for _, elem := range testdata.ReferenceHashes {
+		d := New128()
+		d.Write([]byte(elem.S))
+		assert.Equal(t, murmur3.Uint128{Hi: elem.H64_1, Lo: elem.H64_2}, d.SumUint128())
+	}
+}
+
+func TestByteOrder(t *testing.T) {
+	buf := testdata.RandBytes(100)
+	for n := 0; n <= len(buf); n++ {
+		data := buf[:n]
+		seed := uint32(n)
+		s := uint64(seed)
+
+		d32 := New32WithSeed(seed)
+		d64 := New64WithSeed(s)
+		d128 := New128WithSeed(s, s)
+		d32.Write(data)
+		d64.Write(data)
+		d128.Write(data)
+
+		assert.Equal(t, murmur3.AppendCanonical32(nil, seed, data), d32.SumLE(nil))
+		assert.Equal(t, murmur3.AppendCanonical64(nil, s, data), d64.SumLE(nil))
+		assert.Equal(t, murmur3.AppendCanonical128(nil, s, s, data), d128.SumLE(nil))
+		if testdata.HasCReference {
+			c32 := testdata.SeedSum32Bytes(seed, data)
+			c128 := testdata.SeedSum128Bytes(seed, data)
+			assert.Equal(t, c32[:], d32.SumLE(nil))
+			assert.Equal(t, c128[:8], d64.SumLE(nil))
+			assert.Equal(t, c128[:], d128.SumLE(nil))
+		}
+		assert.Equal(t, d32.Sum(nil), d32.SumBE(nil))
+		assert.Equal(t, d64.Sum(nil), d64.SumBE(nil))
+		assert.Equal(t, d128.Sum(nil), d128.SumBE(nil))
+	}
+}
@@ -0,0 +1,86 @@
+package stackmurmur3
+
+// Snapshot returns a copy of the digest's current state. Digests hold no
+// pointers, so a snapshot or fork of any of them is fully independent: writes
+// to either one do not affect the other.
+func (d *Digest32) Snapshot() Digest32 {
+	return *d
+}
+
+// Fork returns a new digest continuing from the current state.
+func (d *Digest32) Fork() *Digest32 {
+	f := *d
+	return &f
+}
+
+// Snapshot returns a copy of the digest's current state.
+func (d *Digest64) Snapshot() Digest64 {
+	return *d
+}
+
+// Fork returns a new digest continuing from the current state.
+func (d *Digest64) Fork() *Digest64 {
+	f := *d
+	return &f
+}
+
+// Snapshot returns a copy of the digest's current state.
+func (d *Digest128) Snapshot() Digest128 {
+	return *d
+}
+
+// Fork returns a new digest continuing from the current state.
+func (d *Digest128) Fork() *Digest128 {
+	f := *d
+	return &f
+}
+
+// PrefixHasher128 hashes many keys sharing a common prefix. The prefix is
+// digested once and every suffix continues from a copy of that state, so
+// hashing prefix+suffix costs only as much as hashing the suffix.
+type PrefixHasher128 struct {
+	prefix Digest128
+}
+
+// NewPrefixHasher128WithSeed returns a PrefixHasher128 for prefix, with the
+// underlying digest initialized to seed1 and seed2.
+func NewPrefixHasher128WithSeed(seed1, seed2 uint64, prefix []byte) PrefixHasher128 {
+	p := PrefixHasher128{prefix: Digest128{h1: seed1, h2: seed2}}
+	p.prefix.Write(prefix)
+	return p
+}
+
+// NewPrefixHasher128 returns a PrefixHasher128 for prefix.
+func NewPrefixHasher128(prefix []byte) PrefixHasher128 {
+	return NewPrefixHasher128WithSeed(0, 0, prefix)
+}
+
+// PrefixHasher128From returns a PrefixHasher128 continuing from the state of
+// d, which may have been written to any number of times.
+func PrefixHasher128From(d Digest128) PrefixHasher128 {
+	return PrefixHasher128{prefix: d}
+}
+
+// Digest returns a digest positioned after the prefix, for writing suffixes
+// made of multiple parts.
+func (p PrefixHasher128) Digest() Digest128 {
+	return p.prefix
+}
+
+// SumSuffix returns the 128 bit sum of prefix+suffix.
+func (p PrefixHasher128) SumSuffix(suffix []byte) (h1, h2 uint64) {
+	d := p.prefix
+	d.Write(suffix)
+	return d.Sum128()
+}
+
+// SumSuffixes appends the 128 bit sum of prefix+suffix for each suffix to dst
+// and returns the extended slice.
+func (p PrefixHasher128) SumSuffixes(dst [][2]uint64, suffixes [][]byte) [][2]uint64 {
+	for _, suffix := range suffixes {
+		var sum [2]uint64
+		sum[0], sum[1] = p.SumSuffix(suffix)
+		dst = append(dst, sum)
+	}
+	return dst
+}
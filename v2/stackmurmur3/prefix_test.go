@@ -0,0 +1,104 @@
+package stackmurmur3
+
+import (
+	"strconv"
+	"testing"
+
+	murmur3 "github.com/m3db/stackmurmur3/v2"
+	"github.com/m3db/stackmurmur3/v2/testdata"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshotIndependent(t *testing.T) {
+	d := New128WithSeed(3, 4)
+	d.Write([]byte("namespace"))
+	snap := d.Snapshot()
+	fork := d.Fork()
+
+	d.Write([]byte("-a"))
+	fork.Write([]byte("-b"))
+	snap.Write([]byte("-a"))
+
+	a1, a2 := d.Sum128()
+	s1, s2 := snap.Sum128()
+	f1, f2 := fork.Sum128()
+	assert.Equal(t, a1, s1)
+	assert.Equal(t, a2, s2)
+	assert.NotEqual(t, a1, f1)
+
+	w1, w2 := murmur3.SeedSum128(3, 4, []byte("namespace-b"))
+	assert.Equal(t, w1, f1)
+	assert.Equal(t, w2, f2)
+}
+
+func TestPrefixHasher128(t *testing.T) {
+	buf := testdata.RandBytes(100)
+	// Cover prefixes ending mid-block and on block boundaries.
+	for split := 0; split <= len(buf); split++ {
+		p := NewPrefixHasher128WithSeed(7, 7, buf[:split])
+		h1, h2 := p.SumSuffix(buf[split:])
+		w1, w2 := murmur3.SeedSum128(7, 7, buf)
+		if h1 != w1 || h2 != w2 {
+			t.Fatalf("split %d: 0x%x-0x%x (want 0x%x-0x%x)", split, h1, h2, w1, w2)
+		}
+	}
+}
+
+func TestPrefixHasher128SumSuffixes(t *testing.T) {
+	prefix := []byte("ns.tag=")
+	suffixes := [][]byte{[]byte(""), []byte("a"), []byte("some-longer-value")}
+
+	p := NewPrefixHasher128(prefix)
+	sums := p.SumSuffixes(nil, suffixes)
+	assert.Len(t, sums, len(suffixes))
+	for i, s := range suffixes {
+		w1, w2 := murmur3.Sum128(append(append([]byte(nil), prefix...), s...))
+		assert.Equal(t, [2]uint64{w1, w2}, sums[i])
+	}
+
+	d := p.Digest()
+	d.Write(suffixes[2][:4])
+	d.Write(suffixes[2][4:])
+	h1, h2 := d.Sum128()
+	assert.Equal(t, sums[2], [2]uint64{h1, h2})
+
+	q := PrefixHasher128From(p.Digest())
+	assert.Equal(t, p, q)
+}
+
+func TestPrefixHasher128ZeroAlloc(t *testing.T) {
+	p := NewPrefixHasher128([]byte("namespace.tagname="))
+	suffix := []byte("value")
+	suffixes := [][]byte{suffix, suffix}
+	dst := make([][2]uint64, 0, len(suffixes))
+	allocs := testing.AllocsPerRun(100, func() {
+		DoNotOptimize128[0], DoNotOptimize128[1] = p.SumSuffix(suffix)
+		dst = p.SumSuffixes(dst[:0], suffixes)
+	})
+	assert.Equal(t, 0.0, allocs)
+}
+
+func BenchmarkPrefixSuffix(b *testing.B) {
+	const suffixLen = 16
+	buf := testdata.RandBytes(1024 + suffixLen)
+	for prefixLen := 16; prefixLen <= 1024; prefixLen *= 4 {
+		key := buf[:prefixLen+suffixLen]
+		b.Run("Full/"+strconv.Itoa(prefixLen), func(b *testing.B) {
+			b.SetBytes(int64(len(key)))
+			for i := 0; i < b.N; i++ {
+				hasher := New128()
+				hasher.Write(key)
+				DoNotOptimize128[0], DoNotOptimize128[1] = hasher.Sum128()
+			}
+		})
+		b.Run("Prefix/"+strconv.Itoa(prefixLen), func(b *testing.B) {
+			p := NewPrefixHasher128(key[:prefixLen])
+			suffix := key[prefixLen:]
+			b.SetBytes(int64(len(key)))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				DoNotOptimize128[0], DoNotOptimize128[1] = p.SumSuffix(suffix)
+			}
+		})
+	}
+}
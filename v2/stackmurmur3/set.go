@@ -0,0 +1,264 @@
+package stackmurmur3
+
+import (
+	"math/bits"
+
+	murmur3 "github.com/m3db/stackmurmur3/v2"
+)
+
+// Domain separators mixed in on finalization so that a set and a multiset
+// built from the same elements never produce the same sum.
+const (
+	setDomain      = 0x9e3779b97f4a7c15
+	multisetDomain = 0xbf58476d1ce4e5b9
+)
+
+// accum128 is an order independent combination of 128 bit element hashes.
+// Every operation is commutative and invertible, so elements can be added,
+// removed and accumulators merged in any order.
+type accum128 struct {
+	s1, s2 uint64 // 128 bit modular sum of element hashes (s1 is low).
+	x1, x2 uint64 // xor of element hashes.
+	n      uint64 // Element count, modulo 2^64.
+}
+
+func (a *accum128) add(h1, h2, n uint64) {
+	// Adding n copies: the sum gains n*h, the xor only changes for odd n.
+	hi, lo := bits.Mul64(h1, n)
+	hi += h2 * n
+	var c uint64
+	a.s1, c = bits.Add64(a.s1, lo, 0)
+	a.s2, _ = bits.Add64(a.s2, hi, c)
+	if n&1 == 1 {
+		a.x1 ^= h1
+		a.x2 ^= h2
+	}
+	a.n += n
+}
+
+func (a *accum128) remove(h1, h2, n uint64) {
+	hi, lo := bits.Mul64(h1, n)
+	hi += h2 * n
+	var b uint64
+	a.s1, b = bits.Sub64(a.s1, lo, 0)
+	a.s2, _ = bits.Sub64(a.s2, hi, b)
+	if n&1 == 1 {
+		a.x1 ^= h1
+		a.x2 ^= h2
+	}
+	a.n -= n
+}
+
+func (a *accum128) merge(o accum128) {
+	var c uint64
+	a.s1, c = bits.Add64(a.s1, o.s1, 0)
+	a.s2, _ = bits.Add64(a.s2, o.s2, c)
+	a.x1 ^= o.x1
+	a.x2 ^= o.x2
+	a.n += o.n
+}
+
+func (a accum128) sum128(domain uint64) (h1, h2 uint64) {
+	if a.n == 0 && a.s1 == 0 && a.s2 == 0 && a.x1 == 0 && a.x2 == 0 {
+		return 0, 0
+	}
+
+	h1 = a.s1 ^ bits.RotateLeft64(a.x2, 31)
+	h2 = a.s2 ^ bits.RotateLeft64(a.x1, 33)
+
+	h1 ^= a.n
+	h2 ^= a.n ^ domain
+
+	h1 += h2
+	h2 += h1
+
+	h1 = Fmix64(h1)
+	h2 = Fmix64(h2)
+
+	h1 += h2
+	h2 += h1
+
+	return h1, h2
+}
+
+// SetHash128 is an order independent 128 bit hash of a set of byte strings.
+// Elements are hashed individually with the configured seeds and combined
+// commutatively, so the sum does not depend on the order elements were added
+// in. Callers are expected to add each distinct element once; use
+// MultisetHash128 when elements may repeat.
+//
+// The zero value is an empty set hashed with seeds of zero. Like the digests
+// in this package, a SetHash128 is a plain value and can live on the stack.
+type SetHash128 struct {
+	acc   accum128
+	seed1 uint64
+	seed2 uint64
+}
+
+// NewSetHash128WithSeed returns an empty SetHash128 hashing elements with
+// seed1 and seed2.
+func NewSetHash128WithSeed(seed1, seed2 uint64) *SetHash128 {
+	return &SetHash128{seed1: seed1, seed2: seed2}
+}
+
+// NewSetHash128 returns an empty SetHash128.
+func NewSetHash128() *SetHash128 {
+	return NewSetHash128WithSeed(0, 0)
+}
+
+// Add adds the element p to the set.
+func (s *SetHash128) Add(p []byte) {
+	h1, h2 := murmur3.SeedSum128(s.seed1, s.seed2, p)
+	s.acc.add(h1, h2, 1)
+}
+
+// AddString is the string version of Add.
+func (s *SetHash128) AddString(p string) {
+	h1, h2 := murmur3.SeedStringSum128(s.seed1, s.seed2, p)
+	s.acc.add(h1, h2, 1)
+}
+
+// AddSum128 adds an element that has already been hashed with
+// SeedSum128(seed1, seed2, p), using the set's seeds; with seeds of zero, that
+// is Sum128(p).
+func (s *SetHash128) AddSum128(h1, h2 uint64) {
+	s.acc.add(h1, h2, 1)
+}
+
+// Remove removes the element p, previously added, from the set.
+func (s *SetHash128) Remove(p []byte) {
+	h1, h2 := murmur3.SeedSum128(s.seed1, s.seed2, p)
+	s.acc.remove(h1, h2, 1)
+}
+
+// RemoveString is the string version of Remove.
+func (s *SetHash128) RemoveString(p string) {
+	h1, h2 := murmur3.SeedStringSum128(s.seed1, s.seed2, p)
+	s.acc.remove(h1, h2, 1)
+}
+
+// RemoveSum128 removes an element that has already been hashed with the
+// set's seeds, as for AddSum128.
+func (s *SetHash128) RemoveSum128(h1, h2 uint64) {
+	s.acc.remove(h1, h2, 1)
+}
+
+// Merge adds every element of o to s. Both must use the same seeds and be
+// disjoint for the result to equal the hash of their union.
+func (s *SetHash128) Merge(o SetHash128) {
+	s.acc.merge(o.acc)
+}
+
+// Len returns the number of elements in the set.
+func (s SetHash128) Len() int {
+	return int(s.acc.n)
+}
+
+// Reset empties the set, keeping its seeds.
+func (s *SetHash128) Reset() {
+	s.acc = accum128{}
+}
+
+// Sum128 finalizes the hash. The empty set sums to zero.
+func (s SetHash128) Sum128() (h1, h2 uint64) {
+	return s.acc.sum128(setDomain)
+}
+
+// MultisetHash128 is an order independent 128 bit hash of a multiset of byte
+// strings, where adding an element twice differs from adding it once.
+//
+// The zero value is an empty multiset hashed with seeds of zero.
+type MultisetHash128 struct {
+	acc   accum128
+	seed1 uint64
+	seed2 uint64
+}
+
+// NewMultisetHash128WithSeed returns an empty MultisetHash128 hashing elements
+// with seed1 and seed2.
+func NewMultisetHash128WithSeed(seed1, seed2 uint64) *MultisetHash128 {
+	return &MultisetHash128{seed1: seed1, seed2: seed2}
+}
+
+// NewMultisetHash128 returns an empty MultisetHash128.
+func NewMultisetHash128() *MultisetHash128 {
+	return NewMultisetHash128WithSeed(0, 0)
+}
+
+// Add adds one occurrence of p.
+func (m *MultisetHash128) Add(p []byte) {
+	m.AddN(p, 1)
+}
+
+// AddN adds n occurrences of p.
+func (m *MultisetHash128) AddN(p []byte, n uint64) {
+	h1, h2 := murmur3.SeedSum128(m.seed1, m.seed2, p)
+	m.acc.add(h1, h2, n)
+}
+
+// AddString is the string version of Add.
+func (m *MultisetHash128) AddString(p string) {
+	m.AddStringN(p, 1)
+}
+
+// AddStringN is the string version of AddN.
+func (m *MultisetHash128) AddStringN(p string, n uint64) {
+	h1, h2 := murmur3.SeedStringSum128(m.seed1, m.seed2, p)
+	m.acc.add(h1, h2, n)
+}
+
+// AddSum128 adds one occurrence of an element already hashed with
+// SeedSum128(seed1, seed2, p), using the multiset's seeds; with seeds of zero,
+// that is Sum128(p).
+func (m *MultisetHash128) AddSum128(h1, h2 uint64) {
+	m.acc.add(h1, h2, 1)
+}
+
+// Remove removes one occurrence of p.
+func (m *MultisetHash128) Remove(p []byte) {
+	m.RemoveN(p, 1)
+}
+
+// RemoveN removes n occurrences of p.
+func (m *MultisetHash128) RemoveN(p []byte, n uint64) {
+	h1, h2 := murmur3.SeedSum128(m.seed1, m.seed2, p)
+	m.acc.remove(h1, h2, n)
+}
+
+// RemoveString is the string version of Remove.
+func (m *MultisetHash128) RemoveString(p string) {
+	m.RemoveStringN(p, 1)
+}
+
+// RemoveStringN is the string version of RemoveN.
+func (m *MultisetHash128) RemoveStringN(p string, n uint64) {
+	h1, h2 := murmur3.SeedStringSum128(m.seed1, m.seed2, p)
+	m.acc.remove(h1, h2, n)
+}
+
+// RemoveSum128 removes one occurrence of an element already hashed with the
+// multiset's seeds, as for AddSum128.
+func (m *MultisetHash128) RemoveSum128(h1, h2 uint64) {
+	m.acc.remove(h1, h2, 1)
+}
+
+// Merge adds every occurrence in o to m; the result is the hash of the
+// multiset sum. Both must use the same seeds.
+func (m *MultisetHash128) Merge(o MultisetHash128) {
+	m.acc.merge(o.acc)
+}
+
+// Len returns the total number of occurrences in the multiset.
+func (m MultisetHash128) Len() int {
+	return int(m.acc.n)
+}
+
+// Reset empties the multiset, keeping its seeds.
+func (m *MultisetHash128) Reset() {
+	m.acc = accum128{}
+}
+
+// Sum128 finalizes the hash. The empty multiset sums to zero.
+func (m MultisetHash128) Sum128() (h1, h2 uint64) {
+	return m.acc.sum128(multisetDomain)
+}
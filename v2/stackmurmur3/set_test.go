@@ -0,0 +1,142 @@
+package stackmurmur3
+
+import (
+	"math/rand"
+	"testing"
+
+	murmur3 "github.com/m3db/stackmurmur3/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+var setElems = [][]byte{
+	[]byte("__name__=cpu"),
+	[]byte("host=a"),
+	[]byte("dc=us-east"),
+	[]byte("env=prod"),
+	[]byte(""),
+}
+
+func TestSetHash128OrderIndependent(t *testing.T) {
+	var want [2]uint64
+	for i := 0; i < 20; i++ {
+		perm := rand.Perm(len(setElems))
+		s := NewSetHash128()
+		for _, j := range perm {
+			s.Add(setElems[j])
+		}
+		var got [2]uint64
+		got[0], got[1] = s.Sum128()
+		if i == 0 {
+			want = got
+			continue
+		}
+		assert.Equal(t, want, got)
+	}
+	assert.NotEqual(t, [2]uint64{}, want)
+}
+
+func TestSetHash128AddRemove(t *testing.T) {
+	s := NewSetHash128WithSeed(1, 2)
+	s.Add(setElems[0])
+	s.Add(setElems[1])
+	a1, a2 := s.Sum128()
+
+	s.Add(setElems[2])
+	s.RemoveString(string(setElems[2]))
+	b1, b2 := s.Sum128()
+	assert.Equal(t, a1, b1)
+	assert.Equal(t, a2, b2)
+	assert.Equal(t, 2, s.Len())
+
+	s.Remove(setElems[0])
+	s.Remove(setElems[1])
+	e1, e2 := s.Sum128()
+	assert.Zero(t, e1)
+	assert.Zero(t, e2)
+}
+
+func TestSetHash128Merge(t *testing.T) {
+	var all, left, right SetHash128
+	for i, e := range setElems {
+		all.Add(e)
+		if i%2 == 0 {
+			left.Add(e)
+		} else {
+			right.AddSum128(murmur3.Sum128(e))
+		}
+	}
+	left.Merge(right)
+	w1, w2 := all.Sum128()
+	g1, g2 := left.Sum128()
+	assert.Equal(t, w1, g1)
+	assert.Equal(t, w2, g2)
+}
+
+func TestMultisetHash128(t *testing.T) {
+	var once, twice, n MultisetHash128
+	once.Add(setElems[0])
+	twice.Add(setElems[0])
+	twice.AddString(string(setElems[0]))
+	n.AddN(setElems[0], 2)
+
+	o1, _ := once.Sum128()
+	t1, t2 := twice.Sum128()
+	n1, n2 := n.Sum128()
+	assert.NotEqual(t, o1, t1)
+	assert.Equal(t, t1, n1)
+	assert.Equal(t, t2, n2)
+
+	var str MultisetHash128
+	str.AddStringN(string(setElems[0]), 3)
+	str.RemoveStringN(string(setElems[0]), 1)
+	s1, s2 := str.Sum128()
+	assert.Equal(t, [2]uint64{n1, n2}, [2]uint64{s1, s2})
+
+	n.RemoveN(setElems[0], 1)
+	r1, _ := n.Sum128()
+	assert.Equal(t, o1, r1)
+	assert.Equal(t, 1, n.Len())
+}
+
+// The Sum128 variants take elements hashed with the set's own seeds.
+func TestSeededAddSum128(t *testing.T) {
+	s := NewSetHash128WithSeed(1, 2)
+	m := NewMultisetHash128WithSeed(1, 2)
+	s.Add(setElems[0])
+	m.Add(setElems[0])
+	ws1, ws2 := s.Sum128()
+	wm1, wm2 := m.Sum128()
+
+	s.Reset()
+	m.Reset()
+	h1, h2 := murmur3.SeedSum128(1, 2, setElems[0])
+	s.AddSum128(h1, h2)
+	m.AddSum128(h1, h2)
+	gs1, gs2 := s.Sum128()
+	gm1, gm2 := m.Sum128()
+	assert.Equal(t, [2]uint64{ws1, ws2}, [2]uint64{gs1, gs2})
+	assert.Equal(t, [2]uint64{wm1, wm2}, [2]uint64{gm1, gm2})
+}
+
+func TestSetAndMultisetDiffer(t *testing.T) {
+	var s SetHash128
+	var m MultisetHash128
+	for _, e := range setElems {
+		s.Add(e)
+		m.Add(e)
+	}
+	s1, s2 := s.Sum128()
+	m1, m2 := m.Sum128()
+	assert.False(t, s1 == m1 && s2 == m2)
+}
+
+func TestSetHash128ZeroAlloc(t *testing.T) {
+	var s SetHash128
+	allocs := testing.AllocsPerRun(100, func() {
+		s.Add(setElems[0])
+		s.AddString("host=b")
+		s.Remove(setElems[0])
+		DoNotOptimize128[0], DoNotOptimize128[1] = s.Sum128()
+	})
+	assert.Equal(t, 0.0, allocs)
+}
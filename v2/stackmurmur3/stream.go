@@ -0,0 +1,43 @@
+package stackmurmur3
+
+import (
+	murmur3 "github.com/m3db/stackmurmur3/v2"
+)
+
+// Make sure interfaces are correctly implemented.
+var (
+	_ murmur3.Streaming128 = new(Stream128)
+	_ murmur3.OneShot128   = Keyed{}
+)
+
+// Stream128 adapts a Digest128 to murmur3.Streaming128, remembering its
+// seeds so that it can be Reset. Like a Digest128, it is plain data and can
+// live on the stack or be embedded; the generic murmur3 helpers take it by
+// pointer and should be handed the same Stream128 for many keys.
+type Stream128 struct {
+	Digest128
+	seed1 uint64
+	seed2 uint64
+}
+
+// NewStream128WithSeed returns a Stream128 with its internal digests
+// initialized to seed1 and seed2.
+func NewStream128WithSeed(seed1, seed2 uint64) *Stream128 {
+	return &Stream128{Digest128: *New128WithSeed(seed1, seed2), seed1: seed1, seed2: seed2}
+}
+
+// NewStream128 returns a Stream128 for streaming 128 bit sums.
+func NewStream128() *Stream128 {
+	return NewStream128WithSeed(0, 0)
+}
+
+// Write adds p to the digest. It never fails.
+func (s *Stream128) Write(p []byte) (int, error) {
+	s.Digest128.Write(p)
+	return len(p), nil
+}
+
+// Reset returns the digest to its seeded state.
+func (s *Stream128) Reset() {
+	s.Digest128 = *New128WithSeed(s.seed1, s.seed2)
+}
@@ -0,0 +1,62 @@
+package stackmurmur3
+
+import (
+	"testing"
+
+	murmur3 "github.com/m3db/stackmurmur3/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStream128(t *testing.T) {
+	s := NewStream128WithSeed(1, 2)
+	s.Write([]byte("hello, "))
+	s.Write([]byte("world"))
+	h1, h2 := s.Sum128()
+	w1, w2 := murmur3.SeedSum128(1, 2, []byte("hello, world"))
+	assert.Equal(t, [2]uint64{w1, w2}, [2]uint64{h1, h2})
+
+	s.Reset()
+	h1, h2 = murmur3.HashKey128(s, []byte("hello, world"))
+	assert.Equal(t, [2]uint64{w1, w2}, [2]uint64{h1, h2})
+
+	fields := [][]byte{[]byte("cpu.load"), []byte("host=a")}
+	h1, h2 = murmur3.HashFields128(s, fields...)
+	w1, w2 = murmur3.HashFields128(murmur3.SeedNew128(1, 2), fields...)
+	assert.Equal(t, [2]uint64{w1, w2}, [2]uint64{h1, h2})
+
+	k := NewKeyedWithSeed(1, 2)
+	h1, h2 = murmur3.SumFields128(k, fields...)
+	w1, w2 = murmur3.SumFields128(murmur3.Seeded128{Seed1: 1, Seed2: 2}, fields...)
+	assert.Equal(t, [2]uint64{w1, w2}, [2]uint64{h1, h2})
+}
+
+func TestStream128ZeroAlloc(t *testing.T) {
+	s := NewStream128()
+	k := NewKeyed()
+	key := make([]byte, 40)
+	allocs := testing.AllocsPerRun(100, func() {
+		DoNotOptimize128[0], DoNotOptimize128[1] = murmur3.HashKey128(s, key)
+		DoNotOptimize128[0], DoNotOptimize128[1] = murmur3.HashFields128(s, key, key)
+		DoNotOptimize128[0], DoNotOptimize128[1] = murmur3.SumFields128(k, key, key)
+	})
+	assert.Zero(t, allocs)
+}
+
+func BenchmarkStream128HashKey(b *testing.B) {
+	key := make([]byte, 32)
+	b.Run("direct", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			d := New128()
+			d.Write(key)
+			DoNotOptimize128[0], DoNotOptimize128[1] = d.Sum128()
+		}
+	})
+	b.Run("Stream128", func(b *testing.B) {
+		s := NewStream128()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			DoNotOptimize128[0], DoNotOptimize128[1] = murmur3.HashKey128(s, key)
+		}
+	})
+}
@@ -0,0 +1,29 @@
+//go:build !cgo
+// +build !cgo
+
+package testdata
+
+// Without cgo, e.g. when cross compiling tests to run under qemu, the C
+// reference is unavailable. Tests must check HasCReference before calling it.
+
+const cgoEnabled = false
+
+func SeedSum32(seed uint32, data []byte) uint32 {
+	panic("testdata: C reference requires cgo")
+}
+
+func SeedSum64(seed uint32, data []byte) uint64 {
+	panic("testdata: C reference requires cgo")
+}
+
+func SeedSum128(seed uint32, data []byte) (h1, h2 uint64) {
+	panic("testdata: C reference requires cgo")
+}
+
+func SeedSum32Bytes(seed uint32, data []byte) [4]byte {
+	panic("testdata: C reference requires cgo")
+}
+
+func SeedSum128Bytes(seed uint32, data []byte) [16]byte {
+	panic("testdata: C reference requires cgo")
+}
@@ -8,6 +8,8 @@ import "C"
 
 import "unsafe"
 
+const cgoEnabled = true
+
 func SeedSum32(seed uint32, data []byte) uint32 {
 	var p unsafe.Pointer
 	if len(data) > 0 {
@@ -43,3 +45,25 @@ func SeedSum128(seed uint32, data []byte) (h1, h2 uint64) {
 	C.MurmurHash3_x64_128(p, C.int(len(data)), C.uint32_t(seed), unsafe.Pointer(&out))
 	return out.h1, out.h2
 }
+
+// SeedSum32Bytes returns the raw bytes MurmurHash3_x86_32 writes to its
+// output buffer.
+func SeedSum32Bytes(seed uint32, data []byte) (out [4]byte) {
+	var p unsafe.Pointer
+	if len(data) > 0 {
+		p = unsafe.Pointer(&data[0])
+	}
+	C.MurmurHash3_x86_32(p, C.int(len(data)), C.uint32_t(seed), unsafe.Pointer(&out[0]))
+	return out
+}
+
+// SeedSum128Bytes returns the raw bytes MurmurHash3_x64_128 writes to its
+// output buffer.
+func SeedSum128Bytes(seed uint32, data []byte) (out [16]byte) {
+	var p unsafe.Pointer
+	if len(data) > 0 {
+		p = unsafe.Pointer(&data[0])
+	}
+	C.MurmurHash3_x64_128(p, C.int(len(data)), C.uint32_t(seed), unsafe.Pointer(&out[0]))
+	return out
+}
@@ -10,6 +10,11 @@ var IsLittleEndian = func() bool {
 	return (*(*[2]byte)(unsafe.Pointer(&i)))[0] == 1
 }()
 
+// HasCReference reports whether SeedSum32, SeedSum64 and SeedSum128 call the
+// C reference implementation. It requires cgo, and the reference only
+// matches the portable Go hashes on little endian machines.
+var HasCReference = cgoEnabled && IsLittleEndian
+
 var ReferenceHashes = []struct {
 	H32   uint32
 	H64_1 uint64
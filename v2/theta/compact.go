@@ -0,0 +1,177 @@
+package theta
+
+import (
+	"encoding"
+	"encoding/binary"
+	"math"
+	"sort"
+)
+
+// Make sure interfaces are correctly implemented.
+var _ encoding.BinaryMarshaler = new(Compact)
+
+// Compact is an immutable theta sketch: the retained hashes in increasing
+// order, and the theta below which they were retained.
+type Compact struct {
+	theta    uint64
+	hashes   []uint64
+	empty    bool
+	seedHash uint16
+}
+
+// newCompact returns a Compact, treating one that retained nothing at full
+// theta as empty, as the DataSketches libraries do.
+func newCompact(theta uint64, hashes []uint64, empty bool, seedHash uint16) *Compact {
+	if len(hashes) == 0 && theta == MaxTheta {
+		empty = true
+	}
+	if empty {
+		// An empty sketch has no hashes, and the libraries neither store
+		// nor check its seed hash.
+		return &Compact{theta: MaxTheta, empty: true, seedHash: seedHash}
+	}
+	return &Compact{theta: theta, hashes: hashes, seedHash: seedHash}
+}
+
+type hashSlice []uint64
+
+func (h hashSlice) Len() int           { return len(h) }
+func (h hashSlice) Less(i, j int) bool { return h[i] < h[j] }
+func (h hashSlice) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func sortHashes(hashes []uint64) { sort.Sort(hashSlice(hashes)) }
+
+// IsEmpty reports whether the sketch was never updated.
+func (c *Compact) IsEmpty() bool { return c.empty }
+
+// IsEstimationMode reports whether the sketch has dropped hashes, so that
+// Estimate is an estimate rather than an exact count.
+func (c *Compact) IsEstimationMode() bool { return c.theta < MaxTheta }
+
+// NumRetained returns the number of hashes retained.
+func (c *Compact) NumRetained() int { return len(c.hashes) }
+
+// Theta returns the sampling probability of the retained hashes.
+func (c *Compact) Theta() float64 { return float64(c.theta) / MaxTheta }
+
+// ThetaLong returns theta as the 63 bit integer that hashes are compared to.
+func (c *Compact) ThetaLong() uint64 { return c.theta }
+
+// Hashes returns the retained hashes in increasing order. The caller must
+// not modify them.
+func (c *Compact) Hashes() []uint64 { return c.hashes }
+
+// Estimate returns the estimated number of distinct items.
+func (c *Compact) Estimate() float64 { return estimate(len(c.hashes), c.theta) }
+
+// The serialized compact sketch, serial version 3, little endian. The
+// preamble is one to three 8 byte longs:
+//
+//	byte  0     preamble longs
+//	byte  1     serial version, 3
+//	byte  2     family, 3 for compact
+//	bytes 3-4   unused by compact sketches
+//	byte  5     flags
+//	bytes 6-7   seed hash
+//	bytes 8-11  retained count          (preamble longs >= 2)
+//	bytes 12-15 sampling probability p  (preamble longs >= 2)
+//	bytes 16-23 theta                   (preamble longs == 3)
+//
+// followed by the retained hashes. A single hash at full theta takes one
+// preamble long, and an empty sketch is the first preamble long alone.
+const (
+	serialVersion = 3
+	familyCompact = 3
+
+	flagBigEndian  = 1 << 0
+	flagReadOnly   = 1 << 1
+	flagEmpty      = 1 << 2
+	flagCompact    = 1 << 3
+	flagOrdered    = 1 << 4
+	flagSingleItem = 1 << 5
+)
+
+// AppendBinary appends the serialized image of c to dst.
+func (c *Compact) AppendBinary(dst []byte) []byte {
+	preLongs, flags := 1, byte(flagReadOnly|flagCompact|flagOrdered)
+	switch {
+	case c.empty:
+		return append(dst, 1, serialVersion, familyCompact, 0, 0, flags|flagEmpty, 0, 0)
+	case c.theta < MaxTheta:
+		preLongs = 3
+	case len(c.hashes) > 1:
+		preLongs = 2
+	default:
+		flags |= flagSingleItem
+	}
+	dst = append(dst, byte(preLongs), serialVersion, familyCompact, 0, 0, flags)
+	dst = binary.LittleEndian.AppendUint16(dst, c.seedHash)
+	if preLongs > 1 {
+		dst = binary.LittleEndian.AppendUint32(dst, uint32(len(c.hashes)))
+		dst = binary.LittleEndian.AppendUint32(dst, math.Float32bits(1))
+	}
+	if preLongs > 2 {
+		dst = binary.LittleEndian.AppendUint64(dst, c.theta)
+	}
+	for _, h := range c.hashes {
+		dst = binary.LittleEndian.AppendUint64(dst, h)
+	}
+	return dst
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (c *Compact) MarshalBinary() ([]byte, error) {
+	return c.AppendBinary(nil), nil
+}
+
+// ReadCompact reads a compact sketch of serial version 3, as written by
+// AppendBinary or by the Java and C++ libraries, that was built with the
+// update seed seed. Unordered images are sorted.
+func ReadCompact(data []byte, seed uint64) (*Compact, error) {
+	if len(data) < 8 || data[1] != serialVersion || data[2] != familyCompact {
+		return nil, ErrInvalidImage
+	}
+	preLongs, flags := int(data[0]&0x3f), data[5]
+	if flags&flagCompact == 0 || flags&flagBigEndian != 0 || preLongs < 1 || preLongs > 3 {
+		return nil, ErrInvalidImage
+	}
+	seedHash := SeedHash(seed)
+	if flags&flagEmpty != 0 {
+		return newCompact(MaxTheta, nil, true, seedHash), nil
+	}
+	if binary.LittleEndian.Uint16(data[6:]) != seedHash {
+		return nil, ErrSeedMismatch
+	}
+
+	theta, count := uint64(MaxTheta), 1
+	if preLongs > 1 {
+		if len(data) < 8*preLongs {
+			return nil, ErrInvalidImage
+		}
+		count = int(binary.LittleEndian.Uint32(data[8:]))
+	}
+	if preLongs > 2 {
+		theta = binary.LittleEndian.Uint64(data[16:])
+	}
+	data = data[8*preLongs:]
+	if theta == 0 || theta > MaxTheta || count < 0 || len(data)/8 < count {
+		return nil, ErrInvalidImage
+	}
+
+	hashes := make([]uint64, count)
+	for i := range hashes {
+		hashes[i] = binary.LittleEndian.Uint64(data[8*i:])
+		if hashes[i] == 0 || hashes[i] >= theta {
+			return nil, ErrInvalidImage
+		}
+	}
+	if flags&flagOrdered == 0 {
+		sortHashes(hashes)
+	}
+	for i := 1; i < len(hashes); i++ {
+		if hashes[i] <= hashes[i-1] {
+			return nil, ErrInvalidImage
+		}
+	}
+	return newCompact(theta, hashes, false, seedHash), nil
+}
@@ -0,0 +1,157 @@
+package theta
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sketchOf returns a compact sketch of the longs in [from, to).
+func sketchOf(t *testing.T, lgK int, from, to int64) *Compact {
+	s, err := NewSketch(lgK, DefaultUpdateSeed)
+	require.NoError(t, err)
+	for i := from; i < to; i++ {
+		s.UpdateInt64(i)
+	}
+	return s.Compact()
+}
+
+func TestReadCompact(t *testing.T) {
+	for _, n := range []int64{0, 1, 2, 100, 10000} {
+		c := sketchOf(t, 6, 0, n)
+		b, err := c.MarshalBinary()
+		require.NoError(t, err)
+		r, err := ReadCompact(b, DefaultUpdateSeed)
+		require.NoError(t, err)
+		assert.Equal(t, c, r, n)
+		assert.Equal(t, n == 10000, r.IsEstimationMode(), n)
+
+		_, err = ReadCompact(b, 1)
+		if n == 0 {
+			assert.NoError(t, err)
+		} else {
+			assert.Equal(t, ErrSeedMismatch, err)
+		}
+	}
+
+	// An unordered image is sorted.
+	c := sketchOf(t, 6, 0, 3)
+	b := c.AppendBinary(nil)
+	b[5] &^= flagOrdered
+	binary.LittleEndian.PutUint64(b[16:], c.Hashes()[2])
+	binary.LittleEndian.PutUint64(b[32:], c.Hashes()[0])
+	r, err := ReadCompact(b, DefaultUpdateSeed)
+	require.NoError(t, err)
+	assert.Equal(t, c, r)
+
+	good := sketchOf(t, 4, 0, 100).AppendBinary(nil)
+	for name, corrupt := range map[string]func(b []byte) []byte{
+		"short":          func(b []byte) []byte { return b[:7] },
+		"serial version": func(b []byte) []byte { b[1] = 2; return b },
+		"family":         func(b []byte) []byte { b[2] = 2; return b },
+		"not compact":    func(b []byte) []byte { b[5] &^= flagCompact; return b },
+		"big endian":     func(b []byte) []byte { b[5] |= flagBigEndian; return b },
+		"preamble":       func(b []byte) []byte { b[0] = 4; return b },
+		"truncated":      func(b []byte) []byte { return b[:len(b)-1] },
+		"count":          func(b []byte) []byte { b[8]++; return b },
+		"zero theta":     func(b []byte) []byte { binary.LittleEndian.PutUint64(b[16:], 0); return b },
+		"over theta":     func(b []byte) []byte { binary.LittleEndian.PutUint64(b[16:], 1); return b },
+		"duplicate": func(b []byte) []byte {
+			copy(b[32:40], b[24:32])
+			return b
+		},
+	} {
+		_, err := ReadCompact(corrupt(append([]byte(nil), good...)), DefaultUpdateSeed)
+		assert.Equal(t, ErrInvalidImage, err, name)
+	}
+}
+
+func TestSetOperationsExact(t *testing.T) {
+	a := sketchOf(t, 12, 0, 1000)
+	b := sketchOf(t, 12, 500, 2000)
+	empty := sketchOf(t, 12, 0, 0)
+
+	u, err := Union(12, a, b, empty)
+	require.NoError(t, err)
+	assert.Equal(t, 2000.0, u.Estimate())
+	assert.False(t, u.IsEstimationMode())
+	assert.Equal(t, sketchOf(t, 12, 0, 2000), u)
+
+	i, err := Intersect(a, b)
+	require.NoError(t, err)
+	assert.Equal(t, sketchOf(t, 12, 500, 1000), i)
+
+	d, err := AnotB(a, b)
+	require.NoError(t, err)
+	assert.Equal(t, sketchOf(t, 12, 0, 500), d)
+	d, err = AnotB(b, a)
+	require.NoError(t, err)
+	assert.Equal(t, sketchOf(t, 12, 1000, 2000), d)
+
+	// Empty rules.
+	u, _ = Union(12, empty, empty)
+	assert.True(t, u.IsEmpty())
+	u, _ = Union(12)
+	assert.True(t, u.IsEmpty())
+	i, _ = Intersect(a, empty)
+	assert.True(t, i.IsEmpty())
+	i, _ = Intersect(sketchOf(t, 12, 0, 10), sketchOf(t, 12, 10, 20))
+	assert.True(t, i.IsEmpty())
+	assert.Equal(t, []byte{1, 3, 3, 0, 0, 0x1e, 0, 0}, i.AppendBinary(nil))
+	d, _ = AnotB(empty, a)
+	assert.True(t, d.IsEmpty())
+	d, _ = AnotB(a, empty)
+	assert.Equal(t, a, d)
+
+	// Sketches of another seed do not combine.
+	s, err := NewSketch(12, 1)
+	require.NoError(t, err)
+	s.UpdateInt64(1)
+	other := s.Compact()
+	_, err = Union(12, a, other)
+	assert.Equal(t, ErrSeedMismatch, err)
+	_, err = Intersect(a, other)
+	assert.Equal(t, ErrSeedMismatch, err)
+	_, err = AnotB(other, a)
+	assert.Equal(t, ErrSeedMismatch, err)
+	_, err = Union(12, empty, other)
+	assert.NoError(t, err)
+
+	_, err = Union(MaxLgK+1, a)
+	assert.Equal(t, ErrInvalidLgK, err)
+}
+
+func TestSetOperationsEstimate(t *testing.T) {
+	const lgK = 12
+	rse := 3 / math.Sqrt(1<<lgK)
+	a := sketchOf(t, lgK, 0, 200000)
+	b := sketchOf(t, lgK, 100000, 400000)
+
+	u, err := Union(lgK, a, b)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, u.NumRetained(), 1<<lgK)
+	assert.InEpsilon(t, 400000, u.Estimate(), rse)
+
+	// Merging whole streams and merging sketches of their parts agree.
+	parts, err := Union(lgK, sketchOf(t, lgK, 0, 150000), sketchOf(t, lgK, 150000, 400000))
+	require.NoError(t, err)
+	assert.InEpsilon(t, u.Estimate(), parts.Estimate(), rse)
+
+	i, err := Intersect(a, b)
+	require.NoError(t, err)
+	assert.Equal(t, math.Min(a.Theta(), b.Theta()), i.Theta())
+	assert.InEpsilon(t, 100000, i.Estimate(), 2*rse)
+
+	d, err := AnotB(b, a)
+	require.NoError(t, err)
+	assert.InEpsilon(t, 200000, d.Estimate(), 2*rse)
+
+	for _, c := range []*Compact{u, i, d} {
+		r, err := ReadCompact(c.AppendBinary(nil), DefaultUpdateSeed)
+		require.NoError(t, err)
+		assert.Equal(t, c, r)
+	}
+}
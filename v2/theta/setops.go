@@ -0,0 +1,147 @@
+package theta
+
+// The set operations below follow the theta set operations of the
+// DataSketches libraries: the result's theta is the smallest of the inputs',
+// and it retains those hashes below that theta that the operation selects.
+// Empty inputs do not take part in the seed hash check.
+
+func checkSeeds(sketches []*Compact) error {
+	seedHash, seen := uint16(0), false
+	for _, c := range sketches {
+		switch {
+		case c.empty:
+		case !seen:
+			seedHash, seen = c.seedHash, true
+		case c.seedHash != seedHash:
+			return ErrSeedMismatch
+		}
+	}
+	return nil
+}
+
+func minTheta(sketches []*Compact) (theta uint64, seedHash uint16) {
+	theta = MaxTheta
+	if len(sketches) > 0 {
+		seedHash = sketches[0].seedHash
+	}
+	for _, c := range sketches {
+		if c.empty {
+			continue
+		}
+		seedHash = c.seedHash
+		if c.theta < theta {
+			theta = c.theta
+		}
+	}
+	return theta, seedHash
+}
+
+// below returns the prefix of the sorted hashes that is below theta.
+func below(hashes []uint64, theta uint64) []uint64 {
+	n := len(hashes)
+	for n > 0 && hashes[n-1] >= theta {
+		n--
+	}
+	return hashes[:n]
+}
+
+// Union returns the union of sketches, retaining at most 2^lgK hashes.
+func Union(lgK int, sketches ...*Compact) (*Compact, error) {
+	if lgK < MinLgK || lgK > MaxLgK {
+		return nil, ErrInvalidLgK
+	}
+	if err := checkSeeds(sketches); err != nil {
+		return nil, err
+	}
+	theta, seedHash := minTheta(sketches)
+	empty := true
+	var hashes []uint64
+	for _, c := range sketches {
+		if c.empty {
+			continue
+		}
+		empty = false
+		hashes = mergeUnion(make([]uint64, 0, len(hashes)+len(c.hashes)), hashes, below(c.hashes, theta))
+	}
+	if k := 1 << lgK; len(hashes) > k {
+		theta = hashes[k]
+		hashes = hashes[:k]
+	}
+	return newCompact(theta, hashes, empty, seedHash), nil
+}
+
+func mergeUnion(dst, a, b []uint64) []uint64 {
+	for len(a) > 0 && len(b) > 0 {
+		switch {
+		case a[0] < b[0]:
+			dst, a = append(dst, a[0]), a[1:]
+		case a[0] > b[0]:
+			dst, b = append(dst, b[0]), b[1:]
+		default:
+			dst, a, b = append(dst, a[0]), a[1:], b[1:]
+		}
+	}
+	dst = append(dst, a...)
+	return append(dst, b...)
+}
+
+// Intersect returns the intersection of sketches. It is empty if any of
+// them is, or if there are none.
+func Intersect(sketches ...*Compact) (*Compact, error) {
+	if err := checkSeeds(sketches); err != nil {
+		return nil, err
+	}
+	theta, seedHash := minTheta(sketches)
+	if len(sketches) == 0 {
+		return newCompact(theta, nil, true, seedHash), nil
+	}
+	for _, c := range sketches {
+		if c.empty {
+			return newCompact(theta, nil, true, seedHash), nil
+		}
+	}
+	hashes := append([]uint64(nil), below(sketches[0].hashes, theta)...)
+	for _, c := range sketches[1:] {
+		hashes = mergeIntersect(hashes[:0], hashes, c.hashes)
+	}
+	return newCompact(theta, hashes, false, seedHash), nil
+}
+
+// mergeIntersect writes the hashes in both a and b to dst, which may alias a.
+func mergeIntersect(dst, a, b []uint64) []uint64 {
+	for len(a) > 0 && len(b) > 0 {
+		switch {
+		case a[0] < b[0]:
+			a = a[1:]
+		case a[0] > b[0]:
+			b = b[1:]
+		default:
+			dst, a, b = append(dst, a[0]), a[1:], b[1:]
+		}
+	}
+	return dst
+}
+
+// AnotB returns the set difference of a and b: the hashes of a that are not
+// in b. It is empty if a is.
+func AnotB(a, b *Compact) (*Compact, error) {
+	sketches := []*Compact{a, b}
+	if err := checkSeeds(sketches); err != nil {
+		return nil, err
+	}
+	theta, seedHash := minTheta(sketches)
+	if a.empty {
+		return newCompact(theta, nil, true, seedHash), nil
+	}
+	var hashes []uint64
+	bh := b.hashes
+	for _, h := range below(a.hashes, theta) {
+		for len(bh) > 0 && bh[0] < h {
+			bh = bh[1:]
+		}
+		if len(bh) == 0 || bh[0] != h {
+			hashes = append(hashes, h)
+		}
+	}
+	return newCompact(theta, hashes, false, seedHash), nil
+}
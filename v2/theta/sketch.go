@@ -0,0 +1,223 @@
+package theta
+
+// Sketch is an updatable theta sketch. It retains the hashes below theta in
+// an open addressing hash table; once the table holds about 2k hashes, where
+// k is the nominal number of entries, it lowers theta to keep the k smallest,
+// like the QuickSelect sketch of the DataSketches libraries.
+//
+// A Sketch does not allocate once its table has grown to full size.
+type Sketch struct {
+	lgK      uint8
+	seed     uint64
+	seedHash uint16
+	theta    uint64
+	empty    bool
+	table    []uint64 // Zero marks a free slot; hashes are never zero.
+	count    int
+	scratch  []uint64
+}
+
+// NewSketch returns an empty Sketch with 2^lgK nominal entries, hashing with
+// the update seed seed. Most callers want DefaultLgK and DefaultUpdateSeed.
+func NewSketch(lgK int, seed uint64) (*Sketch, error) {
+	if lgK < MinLgK || lgK > MaxLgK {
+		return nil, ErrInvalidLgK
+	}
+	seedHash := SeedHash(seed)
+	if seedHash == 0 {
+		return nil, ErrInvalidSeed
+	}
+	s := &Sketch{lgK: uint8(lgK), seed: seed, seedHash: seedHash}
+	s.Reset()
+	return s, nil
+}
+
+// Reset empties s, keeping its table for reuse.
+func (s *Sketch) Reset() {
+	s.theta = MaxTheta
+	s.empty = true
+	s.count = 0
+	if s.table == nil {
+		s.table = make([]uint64, 1<<minLgTable(s.lgK))
+	}
+	for i := range s.table {
+		s.table[i] = 0
+	}
+}
+
+// The table starts at 32 slots, and doubles up to 2^(lgK+1) slots.
+func minLgTable(lgK uint8) uint8 {
+	if lgK+1 < 5 {
+		return lgK + 1
+	}
+	return 5
+}
+
+// UpdateInt64 adds a long to s.
+func (s *Sketch) UpdateInt64(v int64) {
+	h1, _ := HashInt64(s.seed, v)
+	s.update(h1)
+}
+
+// UpdateFloat64 adds a double to s.
+func (s *Sketch) UpdateFloat64(v float64) {
+	h1, _ := HashFloat64(s.seed, v)
+	s.update(h1)
+}
+
+// UpdateString adds a string to s. Like the DataSketches libraries, it
+// ignores the empty string.
+func (s *Sketch) UpdateString(v string) {
+	if v == "" {
+		return
+	}
+	h1, _ := HashString(s.seed, v)
+	s.update(h1)
+}
+
+// UpdateBytes adds a byte array to s. It ignores an empty array.
+func (s *Sketch) UpdateBytes(v []byte) {
+	if len(v) == 0 {
+		return
+	}
+	h1, _ := HashBytes(s.seed, v)
+	s.update(h1)
+}
+
+func (s *Sketch) update(h1 uint64) {
+	s.empty = false
+	h := thetaHash(h1)
+	if h == 0 || h >= s.theta {
+		return
+	}
+	if !insert(s.table, h) {
+		return
+	}
+	s.count++
+	if s.count > len(s.table)*15/16 {
+		if len(s.table) < 2<<s.lgK {
+			s.grow()
+		} else {
+			s.rebuild()
+		}
+	}
+}
+
+// insert adds h to table, and reports whether it was absent.
+func insert(table []uint64, h uint64) bool {
+	mask := uint64(len(table) - 1)
+	for i := h & mask; ; i = (i + 1) & mask {
+		switch table[i] {
+		case 0:
+			table[i] = h
+			return true
+		case h:
+			return false
+		}
+	}
+}
+
+func (s *Sketch) grow() {
+	old := s.table
+	s.table = make([]uint64, 2*len(old))
+	for _, h := range old {
+		if h != 0 {
+			insert(s.table, h)
+		}
+	}
+}
+
+// rebuild lowers theta to the k+1st smallest hash, keeping the k below it.
+func (s *Sketch) rebuild() {
+	s.scratch = s.retained(s.scratch[:0])
+	k := 1 << s.lgK
+	s.theta = selectNth(s.scratch, k)
+	for i := range s.table {
+		s.table[i] = 0
+	}
+	for _, h := range s.scratch[:k] {
+		insert(s.table, h)
+	}
+	s.count = k
+}
+
+// retained appends the hashes in the table to dst.
+func (s *Sketch) retained(dst []uint64) []uint64 {
+	for _, h := range s.table {
+		if h != 0 {
+			dst = append(dst, h)
+		}
+	}
+	return dst
+}
+
+// selectNth partially sorts a so that a[n] is the value it would hold if a
+// were sorted, with smaller values before it, and returns a[n].
+func selectNth(a []uint64, n int) uint64 {
+	lo, hi := 0, len(a)-1
+	for lo < hi {
+		// Median of three pivot, then Hoare partitioning.
+		mid := lo + (hi-lo)/2
+		if a[mid] < a[lo] {
+			a[mid], a[lo] = a[lo], a[mid]
+		}
+		if a[hi] < a[lo] {
+			a[hi], a[lo] = a[lo], a[hi]
+		}
+		if a[hi] < a[mid] {
+			a[hi], a[mid] = a[mid], a[hi]
+		}
+		pivot := a[mid]
+		i, j := lo, hi
+		for i <= j {
+			for a[i] < pivot {
+				i++
+			}
+			for a[j] > pivot {
+				j--
+			}
+			if i <= j {
+				a[i], a[j] = a[j], a[i]
+				i++
+				j--
+			}
+		}
+		switch {
+		case n <= j:
+			hi = j
+		case n >= i:
+			lo = i
+		default:
+			return a[n]
+		}
+	}
+	return a[n]
+}
+
+// IsEmpty reports whether s has not been updated since it was created or
+// reset.
+func (s *Sketch) IsEmpty() bool { return s.empty }
+
+// NumRetained returns the number of hashes retained.
+func (s *Sketch) NumRetained() int { return s.count }
+
+// Theta returns the sampling probability of the retained hashes.
+func (s *Sketch) Theta() float64 { return float64(s.theta) / MaxTheta }
+
+// Estimate returns the estimated number of distinct items added to s.
+func (s *Sketch) Estimate() float64 { return estimate(s.count, s.theta) }
+
+func estimate(count int, theta uint64) float64 {
+	if theta == MaxTheta {
+		return float64(count)
+	}
+	return float64(count) / (float64(theta) / MaxTheta)
+}
+
+// Compact returns an immutable, ordered snapshot of s, which can be
+// serialized and combined with other sketches.
+func (s *Sketch) Compact() *Compact {
+	hashes := s.retained(make([]uint64, 0, s.count))
+	sortHashes(hashes)
+	return newCompact(s.theta, hashes, s.empty, s.seedHash)
+}
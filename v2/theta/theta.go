@@ -0,0 +1,90 @@
+// Package theta implements Apache DataSketches compatible hashing and theta
+// sketches, which estimate the number of distinct items in a stream and
+// support set operations on those estimates.
+//
+// Items are hashed as the DataSketches libraries hash them, with the x64_128
+// murmur3 seeded with the update seed in both halves, so that a sketch built
+// here and one built by the Java or C++ library from the same items retain
+// the same hashes. A Compact sketch serializes to the compact format of
+// serial version 3, which both libraries, and the Druid and Pinot sketch
+// aggregators built on them, read.
+package theta
+
+import (
+	"errors"
+	"math"
+
+	murmur3 "github.com/m3db/stackmurmur3/v2"
+)
+
+const (
+	// DefaultUpdateSeed is the update seed of the DataSketches libraries.
+	DefaultUpdateSeed = 9001
+
+	// DefaultLgK is the default log2 of the nominal number of entries.
+	DefaultLgK = 12
+	// MinLgK and MaxLgK bound the log2 of the nominal number of entries.
+	MinLgK = 4
+	MaxLgK = 26
+
+	// MaxTheta is the theta of a sketch that has retained every hash, which
+	// stands for a sampling probability of 1.
+	MaxTheta = math.MaxInt64
+)
+
+var (
+	// ErrInvalidLgK is returned for a lgK outside [MinLgK, MaxLgK].
+	ErrInvalidLgK = errors.New("theta: lgK out of range")
+	// ErrInvalidSeed is returned for an update seed whose seed hash is zero,
+	// which the serialized format cannot represent.
+	ErrInvalidSeed = errors.New("theta: invalid update seed")
+	// ErrSeedMismatch is returned when combining or reading sketches built
+	// with a different update seed.
+	ErrSeedMismatch = errors.New("theta: seed hash mismatch")
+	// ErrInvalidImage is returned when reading a malformed or unsupported
+	// serialized sketch.
+	ErrInvalidImage = errors.New("theta: invalid compact sketch image")
+)
+
+// HashInt64 returns the DataSketches hash of a long: the x64_128 sum of its 8
+// little endian bytes.
+func HashInt64(seed uint64, v int64) (h1, h2 uint64) {
+	return murmur3.SeedSum128Uint64(seed, seed, uint64(v))
+}
+
+// HashFloat64 returns the DataSketches hash of a double. As in Java, -0.0 is
+// hashed as 0.0, and every NaN as the canonical NaN, before hashing the bits
+// as a long, so 0.0 hashes like the long 0.
+func HashFloat64(seed uint64, v float64) (h1, h2 uint64) {
+	var bits uint64
+	switch {
+	case v == 0:
+	case v != v:
+		bits = 0x7ff8000000000000
+	default:
+		bits = math.Float64bits(v)
+	}
+	return murmur3.SeedSum128Uint64(seed, seed, bits)
+}
+
+// HashString returns the DataSketches hash of a string: the x64_128 sum of
+// its UTF-8 bytes.
+func HashString(seed uint64, s string) (h1, h2 uint64) {
+	return murmur3.SeedStringSum128(seed, seed, s)
+}
+
+// HashBytes returns the DataSketches hash of a byte array.
+func HashBytes(seed uint64, b []byte) (h1, h2 uint64) {
+	return murmur3.SeedSum128(seed, seed, b)
+}
+
+// SeedHash returns the 16 bit hash of an update seed that serialized sketches
+// carry, so that sketches built with different seeds are not combined.
+func SeedHash(seed uint64) uint16 {
+	h1, _ := murmur3.SeedSum128Uint64(0, 0, seed)
+	return uint16(h1)
+}
+
+// thetaHash turns the h1 of an item's sum into the 63 bit hash retained by a
+// sketch, as the DataSketches update does.
+func thetaHash(h1 uint64) uint64 { return h1 >> 1 }
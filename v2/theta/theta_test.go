@@ -0,0 +1,220 @@
+package theta
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	murmur3 "github.com/m3db/stackmurmur3/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHash(t *testing.T) {
+	// The seed hash of the default seed, as found in DataSketches images.
+	assert.Equal(t, uint16(0x93cc), SeedHash(DefaultUpdateSeed))
+
+	var le [8]byte
+	for _, v := range []int64{0, 1, -1, math.MaxInt64, math.MinInt64, 9001} {
+		binary.LittleEndian.PutUint64(le[:], uint64(v))
+		h1, h2 := murmur3.SeedSum128(DefaultUpdateSeed, DefaultUpdateSeed, le[:])
+		g1, g2 := HashInt64(DefaultUpdateSeed, v)
+		assert.Equal(t, [2]uint64{h1, h2}, [2]uint64{g1, g2}, v)
+	}
+
+	h1, h2 := HashFloat64(DefaultUpdateSeed, 1.5)
+	g1, g2 := HashInt64(DefaultUpdateSeed, int64(math.Float64bits(1.5)))
+	assert.Equal(t, [2]uint64{g1, g2}, [2]uint64{h1, h2})
+
+	zero, _ := HashInt64(DefaultUpdateSeed, 0)
+	negZero, _ := HashFloat64(DefaultUpdateSeed, math.Copysign(0, -1))
+	assert.Equal(t, zero, negZero)
+	nan, _ := HashInt64(DefaultUpdateSeed, 0x7ff8000000000000)
+	for _, bits := range []uint64{0x7ff8000000000000, 0x7ff0000000000001, 0xfff8000000000000, 0x7fffffffffffffff} {
+		h, _ := HashFloat64(DefaultUpdateSeed, math.Float64frombits(bits))
+		assert.Equal(t, nan, h, "%x", bits)
+	}
+
+	h1, h2 = HashString(DefaultUpdateSeed, "héllo")
+	g1, g2 = HashBytes(DefaultUpdateSeed, []byte("héllo"))
+	assert.Equal(t, [2]uint64{g1, g2}, [2]uint64{h1, h2})
+}
+
+// The first preamble long of the empty and single item images is the Java
+// library's EmptyCompactSketch.EMPTY_COMPACT_SKETCH_ARR, and its
+// SingleItemSketch PRE0_LO6_SI with the default seed hash. The hashes, and the exact and
+// estimation images, were computed by this package; TestLibraryImages checks
+// images that the libraries serialized.
+func TestGoldenImages(t *testing.T) {
+	s, err := NewSketch(DefaultLgK, DefaultUpdateSeed)
+	require.NoError(t, err)
+	image := func() string { return hex.EncodeToString(s.Compact().AppendBinary(nil)) }
+
+	assert.Equal(t, "01030300001e0000", image())
+
+	// One long: a single item image.
+	s.UpdateInt64(1)
+	assert.Equal(t, "01030300003acc93"+"15f97dcbbd86a105", image())
+
+	// Exact mode: count and p, then the hashes in order.
+	s.UpdateInt64(2)
+	s.UpdateString("a")
+	s.UpdateString("")
+	s.UpdateBytes(nil)
+	assert.Equal(t, "02030300001acc93"+"03000000"+"0000803f"+
+		"15f97dcbbd86a105"+"c397fc1281709d1e"+"17c11d528507017b", image())
+
+	// Estimation mode: count, p and theta, then the hashes in order.
+	s, err = NewSketch(MinLgK, DefaultUpdateSeed)
+	require.NoError(t, err)
+	for i := 0; i < 40; i++ {
+		s.UpdateInt64(int64(i))
+	}
+	b := s.Compact().AppendBinary(nil)
+	assert.Equal(t, "03030300001acc93"+"12000000"+"0000803f"+"f796fbd50949aa25", hex.EncodeToString(b[:24]))
+	assert.Equal(t, "fb38798913248f01", hex.EncodeToString(b[24:32]))
+	assert.Len(t, b, 24+18*8)
+}
+
+// TestLibraryImages reads the compact sketches that datasketches-java and
+// datasketches-cpp serialize for their cross-language tests, copied into
+// testdata under the names the libraries give them: theta_n<N>_java.sk and
+// theta_n<N>_cpp.sk, each a sketch of the longs 0 to N-1 with the default lgK
+// and seed. Each must be read and written back byte for byte, and retain the
+// same hashes below its theta as this package's sketch of the same input.
+// The test fails unless both libraries provide an exact and an estimation
+// mode image, since it would otherwise check nothing.
+func TestLibraryImages(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "theta_n*_*.sk"))
+	require.NoError(t, err)
+	seen := make(map[string]bool)
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".sk")
+		n, lib, ok := strings.Cut(strings.TrimPrefix(name, "theta_n"), "_")
+		count, err := strconv.Atoi(n)
+		if !ok || err != nil {
+			continue
+		}
+		b, err := os.ReadFile(file)
+		require.NoError(t, err)
+		c, err := ReadCompact(b, DefaultUpdateSeed)
+		require.NoError(t, err, file)
+		assert.Equal(t, count == 0, c.IsEmpty(), file)
+		assert.Equal(t, hex.EncodeToString(b), hex.EncodeToString(c.AppendBinary(nil)), file)
+
+		s, err := NewSketch(DefaultLgK, DefaultUpdateSeed)
+		require.NoError(t, err)
+		for i := 0; i < count; i++ {
+			s.UpdateInt64(int64(i))
+		}
+		want := s.Compact()
+		theta := c.ThetaLong()
+		if want.ThetaLong() < theta {
+			theta = want.ThetaLong()
+		}
+		assert.Equal(t, below(want.Hashes(), theta), below(c.Hashes(), theta), file)
+		if !c.IsEstimationMode() {
+			assert.Equal(t, float64(count), c.Estimate(), file)
+		}
+		mode := "exact"
+		if c.IsEstimationMode() {
+			mode = "estimation"
+		}
+		seen[lib+" "+mode] = true
+	}
+	for _, lib := range []string{"java", "cpp"} {
+		for _, mode := range []string{"exact", "estimation"} {
+			if !seen[lib+" "+mode] {
+				t.Errorf("no %s mode image serialized by datasketches-%s in testdata", mode, lib)
+			}
+		}
+	}
+}
+
+func TestSketch(t *testing.T) {
+	_, err := NewSketch(MinLgK-1, DefaultUpdateSeed)
+	assert.Equal(t, ErrInvalidLgK, err)
+	_, err = NewSketch(MaxLgK+1, DefaultUpdateSeed)
+	assert.Equal(t, ErrInvalidLgK, err)
+
+	s, err := NewSketch(DefaultLgK, DefaultUpdateSeed)
+	require.NoError(t, err)
+	assert.True(t, s.IsEmpty())
+	assert.Equal(t, 0.0, s.Estimate())
+
+	// Exact below k, and duplicates count once.
+	for i := 0; i < 3000; i++ {
+		s.UpdateInt64(int64(i % 1000))
+		s.UpdateFloat64(float64(i%1000) + 0.5)
+	}
+	assert.False(t, s.IsEmpty())
+	assert.Equal(t, 1.0, s.Theta())
+	assert.Equal(t, 2000.0, s.Estimate())
+	assert.Equal(t, 2000, s.NumRetained())
+
+	// Within three standard errors of 1/sqrt(k) in estimation mode.
+	for _, n := range []int{10000, 100000, 1000000} {
+		s.Reset()
+		for i := 0; i < n; i++ {
+			s.UpdateInt64(int64(i))
+		}
+		assert.Less(t, s.Theta(), 1.0)
+		assert.LessOrEqual(t, s.NumRetained(), 2<<DefaultLgK)
+		assert.InEpsilon(t, float64(n), s.Estimate(), 3/math.Sqrt(1<<DefaultLgK), n)
+		assert.Equal(t, s.Estimate(), s.Compact().Estimate())
+	}
+}
+
+func TestSelectNth(t *testing.T) {
+	for _, n := range []int{1, 2, 3, 10, 100, 1000} {
+		a := make([]uint64, n)
+		for i := range a {
+			h, _ := murmur3.Sum128Uint64(uint64(i % (n/3 + 1)))
+			a[i] = h
+		}
+		sorted := append([]uint64(nil), a...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		for _, k := range []int{0, n / 2, n - 1} {
+			b := append([]uint64(nil), a...)
+			assert.Equal(t, sorted[k], selectNth(b, k), "n=%d k=%d", n, k)
+			for _, v := range b[:k] {
+				assert.LessOrEqual(t, v, b[k])
+			}
+			for _, v := range b[k:] {
+				assert.GreaterOrEqual(t, v, b[k])
+			}
+		}
+	}
+}
+
+func TestZeroAlloc(t *testing.T) {
+	s, err := NewSketch(MinLgK, DefaultUpdateSeed)
+	require.NoError(t, err)
+	for i := 0; i < 1000; i++ {
+		s.UpdateInt64(int64(i))
+	}
+	b := []byte("abcdefghijklmnopqrstuvwxyz")
+	i := 0
+	allocs := testing.AllocsPerRun(1000, func() {
+		i++
+		s.UpdateInt64(int64(i))
+		s.UpdateFloat64(float64(i))
+		s.UpdateString("abcdefghijklmnopqrstuvwxyz"[i%26:])
+		s.UpdateBytes(b[:i%26])
+	})
+	assert.Zero(t, allocs)
+}
+
+func BenchmarkUpdateInt64(b *testing.B) {
+	s, _ := NewSketch(DefaultLgK, DefaultUpdateSeed)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		s.UpdateInt64(int64(i))
+	}
+}
@@ -0,0 +1,309 @@
+package murmur3
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/bits"
+	"strconv"
+)
+
+// Make sure interfaces are correctly implemented.
+var (
+	_ encoding.TextMarshaler     = Uint128{}
+	_ encoding.TextUnmarshaler   = new(Uint128)
+	_ encoding.BinaryMarshaler   = Uint128{}
+	_ encoding.BinaryUnmarshaler = new(Uint128)
+	_ json.Marshaler             = Uint128{}
+	_ json.Unmarshaler           = new(Uint128)
+	_ Uint128Summer              = new(digest128)
+)
+
+// ErrInvalidUint128 is returned when parsing or decoding a malformed Uint128.
+var ErrInvalidUint128 = errors.New("murmur3: invalid Uint128")
+
+// Uint128 is a 128 bit sum as a single comparable value, usable as a map key.
+// Hi holds h1 and Lo holds h2, so that the number Hi<<64 | Lo has the same big
+// endian bytes as Sum.
+type Uint128 struct {
+	Hi, Lo uint64
+}
+
+// SumUint128 returns Sum128(data) as a Uint128.
+func SumUint128(data []byte) Uint128 {
+	h1, h2 := Sum128(data)
+	return Uint128{h1, h2}
+}
+
+// SeedSumUint128 returns SeedSum128(seed1, seed2, data) as a Uint128.
+func SeedSumUint128(seed1, seed2 uint64, data []byte) Uint128 {
+	h1, h2 := SeedSum128(seed1, seed2, data)
+	return Uint128{h1, h2}
+}
+
+// StringSumUint128 is the string version of SumUint128.
+func StringSumUint128(data string) Uint128 {
+	h1, h2 := StringSum128(data)
+	return Uint128{h1, h2}
+}
+
+// SeedStringSumUint128 is the string version of SeedSumUint128.
+func SeedStringSumUint128(seed1, seed2 uint64, data string) Uint128 {
+	h1, h2 := SeedStringSum128(seed1, seed2, data)
+	return Uint128{h1, h2}
+}
+
+// Uint128Summer is implemented by the 128 bit digests of this package, those
+// returned by New128 and SeedNew128, and of the stackmurmur3 package, and
+// returns their Sum128 as a Uint128:
+//
+//	sum := murmur3.New128().(murmur3.Uint128Summer).SumUint128()
+type Uint128Summer interface {
+	SumUint128() Uint128
+}
+
+// SumUint128 returns the digest's Sum128 as a Uint128.
+func (d *digest128) SumUint128() Uint128 {
+	h1, h2 := d.Sum128()
+	return Uint128{h1, h2}
+}
+
+// IsZero reports whether u is zero.
+func (u Uint128) IsZero() bool { return u == Uint128{} }
+
+// Cmp returns -1, 0 or +1 depending on whether u is less than, equal to or
+// greater than v.
+func (u Uint128) Cmp(v Uint128) int {
+	switch {
+	case u == v:
+		return 0
+	case u.Less(v):
+		return -1
+	default:
+		return +1
+	}
+}
+
+// Less reports whether u < v.
+func (u Uint128) Less(v Uint128) bool {
+	return u.Hi < v.Hi || u.Hi == v.Hi && u.Lo < v.Lo
+}
+
+// Add returns u+v, wrapping around at 2^128.
+func (u Uint128) Add(v Uint128) Uint128 {
+	lo, carry := bits.Add64(u.Lo, v.Lo, 0)
+	hi, _ := bits.Add64(u.Hi, v.Hi, carry)
+	return Uint128{hi, lo}
+}
+
+// Sub returns u-v, wrapping around at 2^128.
+func (u Uint128) Sub(v Uint128) Uint128 {
+	lo, borrow := bits.Sub64(u.Lo, v.Lo, 0)
+	hi, _ := bits.Sub64(u.Hi, v.Hi, borrow)
+	return Uint128{hi, lo}
+}
+
+// mul64 returns u*v, wrapping around at 2^128.
+func (u Uint128) mul64(v uint64) Uint128 {
+	hi, lo := bits.Mul64(u.Lo, v)
+	return Uint128{hi + u.Hi*v, lo}
+}
+
+// divmod64 returns u/v and u%v.
+func (u Uint128) divmod64(v uint64) (Uint128, uint64) {
+	q := Uint128{Hi: u.Hi / v}
+	var r uint64
+	q.Lo, r = bits.Div64(u.Hi%v, u.Lo, v)
+	return q, r
+}
+
+// SplitRange splits the range from start to end into n contiguous parts of
+// sizes differing by at most one, and returns their n+1 boundaries, starting
+// with start and ending with end. Part i spans [b[i], b[i+1]).
+//
+// Ranges wrap around at 2^128 like a token ring: end may be below start, and
+// start == end denotes the whole ring. SplitRange panics if n < 1.
+func SplitRange(start, end Uint128, n int) []Uint128 {
+	if n < 1 {
+		panic("murmur3: SplitRange with n < 1")
+	}
+	nn := uint64(n)
+	var q Uint128
+	var r uint64
+	if start == end {
+		// The width 2^128 does not fit, so divide 2^128-1 and add one back.
+		q, r = Uint128{^uint64(0), ^uint64(0)}.divmod64(nn)
+		if r++; r == nn {
+			q, r = q.Add(Uint128{Lo: 1}), 0
+		}
+	} else {
+		q, r = end.Sub(start).divmod64(nn)
+	}
+
+	// Boundary i is start + i*q + floor(i*r/n): the first parts are the short
+	// ones, and the remainder spreads evenly.
+	b := make([]Uint128, n+1)
+	for i := 0; i <= n; i++ {
+		hi, lo := bits.Mul64(uint64(i), r)
+		extra, _ := bits.Div64(hi, lo, nn)
+		b[i] = start.Add(q.mul64(uint64(i))).Add(Uint128{Lo: extra})
+	}
+	return b
+}
+
+// Bytes returns the 16 big endian bytes of u, as produced by Sum.
+func (u Uint128) Bytes() [16]byte {
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[:8], u.Hi)
+	binary.BigEndian.PutUint64(b[8:], u.Lo)
+	return b
+}
+
+// String returns u as 32 lowercase hex digits, the hex encoding of Bytes.
+func (u Uint128) String() string {
+	b := u.Bytes()
+	return hex.EncodeToString(b[:])
+}
+
+// Base64 returns the unpadded URL safe base64 encoding of Bytes.
+func (u Uint128) Base64() string {
+	b := u.Bytes()
+	return base64.RawURLEncoding.EncodeToString(b[:])
+}
+
+// Decimal returns u in base 10.
+func (u Uint128) Decimal() string {
+	if u.Hi == 0 {
+		return strconv.FormatUint(u.Lo, 10)
+	}
+	// Peel off 19 digit chunks, the largest power of ten below 2^64.
+	const chunk = 1e19
+	var buf [40]byte
+	i := len(buf)
+	for u.Hi != 0 {
+		var r uint64
+		u, r = u.divmod64(chunk)
+		s := strconv.FormatUint(r, 10)
+		i -= 19
+		for j := 0; j < 19-len(s); j++ {
+			buf[i+j] = '0'
+		}
+		copy(buf[i+19-len(s):], s)
+	}
+	return strconv.FormatUint(u.Lo, 10) + string(buf[i:])
+}
+
+// ParseUint128 parses the String form of a Uint128: 1 to 32 hex digits.
+func ParseUint128(s string) (Uint128, error) {
+	if len(s) == 0 || len(s) > 32 {
+		return Uint128{}, ErrInvalidUint128
+	}
+	var u Uint128
+	for i := 0; i < len(s); i++ {
+		var d byte
+		switch c := s[i]; {
+		case '0' <= c && c <= '9':
+			d = c - '0'
+		case 'a' <= c && c <= 'f':
+			d = c - 'a' + 10
+		case 'A' <= c && c <= 'F':
+			d = c - 'A' + 10
+		default:
+			return Uint128{}, ErrInvalidUint128
+		}
+		u = Uint128{u.Hi<<4 | u.Lo>>60, u.Lo<<4 | uint64(d)}
+	}
+	return u, nil
+}
+
+// ParseUint128Base64 parses the Base64 form of a Uint128.
+func ParseUint128Base64(s string) (Uint128, error) {
+	var b [16]byte
+	if base64.RawURLEncoding.DecodedLen(len(s)) != len(b) {
+		return Uint128{}, ErrInvalidUint128
+	}
+	if _, err := base64.RawURLEncoding.Decode(b[:], []byte(s)); err != nil {
+		return Uint128{}, ErrInvalidUint128
+	}
+	return uint128FromBytes(b[:]), nil
+}
+
+// ParseUint128Decimal parses the Decimal form of a Uint128.
+func ParseUint128Decimal(s string) (Uint128, error) {
+	if len(s) == 0 {
+		return Uint128{}, ErrInvalidUint128
+	}
+	var u Uint128
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < '0' || c > '9' {
+			return Uint128{}, ErrInvalidUint128
+		}
+		hi, lo := bits.Mul64(u.Lo, 10)
+		top, carry := bits.Mul64(u.Hi, 10)
+		hi, c1 := bits.Add64(hi, carry, 0)
+		lo, c2 := bits.Add64(lo, uint64(c-'0'), 0)
+		hi, c3 := bits.Add64(hi, 0, c2)
+		if top != 0 || c1 != 0 || c3 != 0 {
+			return Uint128{}, ErrInvalidUint128
+		}
+		u = Uint128{hi, lo}
+	}
+	return u, nil
+}
+
+func uint128FromBytes(b []byte) Uint128 {
+	return Uint128{binary.BigEndian.Uint64(b[:8]), binary.BigEndian.Uint64(b[8:])}
+}
+
+// MarshalText implements encoding.TextMarshaler with the String form.
+func (u Uint128) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, accepting what
+// ParseUint128 accepts.
+func (u *Uint128) UnmarshalText(text []byte) error {
+	v, err := ParseUint128(string(text))
+	if err != nil {
+		return err
+	}
+	*u = v
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler with Bytes.
+func (u Uint128) MarshalBinary() ([]byte, error) {
+	b := u.Bytes()
+	return b[:], nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. It requires exactly
+// 16 bytes.
+func (u *Uint128) UnmarshalBinary(data []byte) error {
+	if len(data) != 16 {
+		return ErrInvalidUint128
+	}
+	*u = uint128FromBytes(data)
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler with a string holding the String
+// form; JSON numbers cannot hold 128 bits exactly.
+func (u Uint128) MarshalJSON() ([]byte, error) {
+	b := make([]byte, 0, 34)
+	b = append(b, '"')
+	b = append(b, u.String()...)
+	return append(b, '"'), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler for the output of MarshalJSON.
+func (u *Uint128) UnmarshalJSON(data []byte) error {
+	if len(data) < 2 || data[0] != '"' || data[len(data)-1] != '"' {
+		return ErrInvalidUint128
+	}
+	return u.UnmarshalText(data[1 : len(data)-1])
+}
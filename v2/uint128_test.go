@@ -0,0 +1,178 @@
+package murmur3
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"testing"
+	"testing/quick"
+
+	"github.com/m3db/stackmurmur3/v2/testdata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func toBig(u Uint128) *big.Int {
+	b := u.Bytes()
+	return new(big.Int).SetBytes(b[:])
+}
+
+var two128 = new(big.Int).Lsh(big.NewInt(1), 128)
+
+func TestSumUint128(t *testing.T) {
+	for _, elem := range testdata.ReferenceHashes {
+		want := Uint128{elem.H64_1, elem.H64_2}
+		assert.Equal(t, want, SumUint128([]byte(elem.S)))
+		assert.Equal(t, want, StringSumUint128(elem.S))
+		assert.Equal(t, want, SeedSumUint128(0, 0, []byte(elem.S)))
+		assert.Equal(t, want, SeedStringSumUint128(0, 0, elem.S))
+
+		d := New128()
+		d.Write([]byte(elem.S))
+		assert.Equal(t, want, d.(Uint128Summer).SumUint128())
+
+		b := want.Bytes()
+		assert.Equal(t, d.Sum(nil), b[:])
+		assert.Equal(t, fmt.Sprintf("%016x%016x", elem.H64_1, elem.H64_2), want.String())
+	}
+}
+
+func TestUint128Order(t *testing.T) {
+	us := []Uint128{{1, 0}, {0, 2}, {0, 0}, {^uint64(0), 0}, {0, ^uint64(0)}, {1, 1}}
+	sort.Slice(us, func(i, j int) bool { return us[i].Less(us[j]) })
+	assert.Equal(t, []Uint128{{0, 0}, {0, 2}, {0, ^uint64(0)}, {1, 0}, {1, 1}, {^uint64(0), 0}}, us)
+
+	assert.True(t, Uint128{}.IsZero())
+	assert.False(t, Uint128{Lo: 1}.IsZero())
+	assert.Equal(t, -1, Uint128{0, 5}.Cmp(Uint128{1, 0}))
+	assert.Equal(t, 0, Uint128{1, 0}.Cmp(Uint128{1, 0}))
+	assert.Equal(t, 1, Uint128{1, 0}.Cmp(Uint128{0, 5}))
+}
+
+func TestUint128Arithmetic(t *testing.T) {
+	f := func(a, b Uint128) bool {
+		sum := new(big.Int).Add(toBig(a), toBig(b))
+		diff := new(big.Int).Sub(toBig(a), toBig(b))
+		return toBig(a.Add(b)).Cmp(sum.Mod(sum, two128)) == 0 &&
+			toBig(a.Sub(b)).Cmp(diff.Mod(diff, two128)) == 0 &&
+			a.Add(b).Sub(b) == a &&
+			(a.Cmp(b) < 0) == (toBig(a).Cmp(toBig(b)) < 0)
+	}
+	require.NoError(t, quick.Check(f, nil))
+}
+
+func TestUint128Strings(t *testing.T) {
+	f := func(u Uint128) bool {
+		hexOK := u.String() == leftPad(toBig(u).Text(16), 32)
+		decOK := u.Decimal() == toBig(u).String()
+		p1, err1 := ParseUint128(u.String())
+		p2, err2 := ParseUint128Base64(u.Base64())
+		p3, err3 := ParseUint128Decimal(u.Decimal())
+		return hexOK && decOK && err1 == nil && err2 == nil && err3 == nil &&
+			p1 == u && p2 == u && p3 == u
+	}
+	require.NoError(t, quick.Check(f, nil))
+
+	for _, u := range []Uint128{{}, {0, 1}, {1, 0}, {0, 1e19}, {^uint64(0), ^uint64(0)}} {
+		assert.Equal(t, toBig(u).String(), u.Decimal())
+	}
+	assert.Equal(t, "340282366920938463463374607431768211455", Uint128{^uint64(0), ^uint64(0)}.Decimal())
+
+	u, err := ParseUint128("1F")
+	require.NoError(t, err)
+	assert.Equal(t, Uint128{Lo: 31}, u)
+
+	for _, s := range []string{"", "g", "0x1", "123456789012345678901234567890123"} {
+		_, err := ParseUint128(s)
+		assert.Equal(t, ErrInvalidUint128, err, s)
+	}
+	for _, s := range []string{"", "-1", "1.0", "340282366920938463463374607431768211456"} {
+		_, err := ParseUint128Decimal(s)
+		assert.Equal(t, ErrInvalidUint128, err, s)
+	}
+	for _, s := range []string{"", "AAAA", "AAAAAAAAAAAAAAAAAAAAA=", "AAAAAAAAAAAAAAAAAAAAA!"} {
+		_, err := ParseUint128Base64(s)
+		assert.Equal(t, ErrInvalidUint128, err, s)
+	}
+}
+
+func leftPad(s string, n int) string {
+	for len(s) < n {
+		s = "0" + s
+	}
+	return s
+}
+
+func TestUint128Marshal(t *testing.T) {
+	u := SumUint128([]byte("hello"))
+
+	type doc struct {
+		Sum  Uint128
+		Keys map[Uint128]int
+	}
+	in := doc{Sum: u, Keys: map[Uint128]int{u: 1}}
+	b, err := json.Marshal(in)
+	require.NoError(t, err)
+	assert.Equal(t, `{"Sum":"`+u.String()+`","Keys":{"`+u.String()+`":1}}`, string(b))
+	var out doc
+	require.NoError(t, json.Unmarshal(b, &out))
+	assert.Equal(t, in, out)
+	assert.Error(t, json.Unmarshal([]byte(`{"Sum":1}`), &out))
+
+	bin, err := u.MarshalBinary()
+	require.NoError(t, err)
+	var v Uint128
+	require.NoError(t, v.UnmarshalBinary(bin))
+	assert.Equal(t, u, v)
+	assert.Equal(t, ErrInvalidUint128, v.UnmarshalBinary(bin[1:]))
+}
+
+func TestSplitRange(t *testing.T) {
+	max := Uint128{^uint64(0), ^uint64(0)}
+	cases := []struct {
+		start, end Uint128
+		n          int
+	}{
+		{Uint128{}, Uint128{Lo: 10}, 3},
+		{Uint128{Lo: 5}, Uint128{Lo: 5}, 1},
+		{Uint128{}, Uint128{}, 4},
+		{Uint128{}, Uint128{}, 3},
+		{Uint128{Lo: 7}, Uint128{Lo: 7}, 7},
+		{max, Uint128{Lo: 3}, 2},
+		{Uint128{1 << 63, 0}, Uint128{1<<63 - 1, 0}, 5},
+		{Uint128{Lo: 1}, Uint128{Lo: 3}, 5},
+	}
+	for _, c := range cases {
+		b := SplitRange(c.start, c.end, c.n)
+		require.Len(t, b, c.n+1)
+		assert.Equal(t, c.start, b[0])
+		assert.Equal(t, c.end, b[c.n])
+
+		width := new(big.Int).Sub(toBig(c.end), toBig(c.start))
+		width.Mod(width, two128)
+		if width.Sign() == 0 {
+			width.Set(two128)
+		}
+		total := new(big.Int)
+		var minPart, maxPart *big.Int
+		for i := 0; i < c.n; i++ {
+			part := toBig(b[i+1].Sub(b[i]))
+			if c.n == 1 && c.start == c.end {
+				part.Set(two128)
+			}
+			total.Add(total, part)
+			if minPart == nil || part.Cmp(minPart) < 0 {
+				minPart = part
+			}
+			if maxPart == nil || part.Cmp(maxPart) > 0 {
+				maxPart = part
+			}
+		}
+		assert.Equal(t, 0, total.Cmp(width), "%+v", c)
+		assert.True(t, new(big.Int).Sub(maxPart, minPart).Cmp(big.NewInt(1)) <= 0, "%+v", c)
+	}
+
+	assert.Equal(t, []Uint128{{}, {1 << 62, 0}, {1 << 63, 0}, {3 << 62, 0}, {}}, SplitRange(Uint128{}, Uint128{}, 4))
+	assert.Panics(t, func() { SplitRange(Uint128{}, Uint128{}, 0) })
+}
@@ -0,0 +1,206 @@
+// Package uuid derives deterministic, name-based RFC 9562 version 8 UUIDs
+// from murmur3 128 bit sums.
+//
+// A UUID is SeedSum128 of a name, seeded with the two big endian halves of a
+// namespace UUID. Its 16 bytes are h1 then h2 in big endian order, with the
+// version and variant bits overwritten as RFC 9562 section 5.8 requires. This
+// leaves 122 hashed bits. Like version 5 UUIDs, the same namespace and name
+// always give the same UUID. Unlike them, no SHA-1 is involved, and murmur3 is
+// not collision resistant against adversarial names.
+package uuid
+
+import (
+	"encoding"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+
+	murmur3 "github.com/m3db/stackmurmur3/v2"
+	"github.com/m3db/stackmurmur3/v2/stackmurmur3"
+)
+
+// Make sure interfaces are correctly implemented.
+var (
+	_ encoding.TextMarshaler   = UUID{}
+	_ encoding.TextUnmarshaler = new(UUID)
+)
+
+// ErrInvalid is returned when parsing a malformed UUID.
+var ErrInvalid = errors.New("uuid: invalid UUID")
+
+// UUID is a 16 byte UUID in network byte order.
+type UUID [16]byte
+
+// The namespaces of RFC 9562 section 6.6, for names of well known kinds.
+var (
+	NamespaceDNS  = MustParse("6ba7b810-9dad-11d1-80b4-00c04fd430c8")
+	NamespaceURL  = MustParse("6ba7b811-9dad-11d1-80b4-00c04fd430c8")
+	NamespaceOID  = MustParse("6ba7b812-9dad-11d1-80b4-00c04fd430c8")
+	NamespaceX500 = MustParse("6ba7b814-9dad-11d1-80b4-00c04fd430c8")
+)
+
+// Nil is the all zero UUID.
+var Nil UUID
+
+// Sum returns the version 8 UUID of name in namespace.
+func Sum(namespace UUID, name []byte) UUID {
+	seed1, seed2 := namespace.seeds()
+	return FromSum128(murmur3.SeedSum128(seed1, seed2, name))
+}
+
+// SumString is the string version of Sum.
+func SumString(namespace UUID, name string) UUID {
+	seed1, seed2 := namespace.seeds()
+	return FromSum128(murmur3.SeedStringSum128(seed1, seed2, name))
+}
+
+// FromSum128 returns the version 8 UUID holding the 128 bit sum h1, h2.
+func FromSum128(h1, h2 uint64) UUID {
+	var u UUID
+	binary.BigEndian.PutUint64(u[:8], h1)
+	binary.BigEndian.PutUint64(u[8:], h2)
+	u[6] = u[6]&0x0f | 0x80 // Version 8.
+	u[8] = u[8]&0x3f | 0x80 // Variant 10x, RFC 9562.
+	return u
+}
+
+func (u UUID) seeds() (seed1, seed2 uint64) {
+	return binary.BigEndian.Uint64(u[:8]), binary.BigEndian.Uint64(u[8:])
+}
+
+// Version returns the version field of u, 8 for the UUIDs of this package.
+func (u UUID) Version() int {
+	return int(u[6] >> 4)
+}
+
+// Hasher builds the UUID of a name written in several parts; the UUID is
+// that of the concatenated parts. Like the stackmurmur3 digests it wraps, it
+// does not allocate.
+type Hasher struct {
+	d         stackmurmur3.Digest128
+	namespace UUID
+}
+
+// NewHasher returns a Hasher for names in namespace.
+func NewHasher(namespace UUID) *Hasher {
+	h := &Hasher{namespace: namespace}
+	h.Reset()
+	return h
+}
+
+// Write adds p to the name. It never fails.
+func (h *Hasher) Write(p []byte) (int, error) {
+	h.d.Write(p)
+	return len(p), nil
+}
+
+// UUID returns the UUID of the name written so far.
+func (h *Hasher) UUID() UUID {
+	return FromSum128(h.d.Sum128())
+}
+
+// Reset clears the name, keeping the namespace.
+func (h *Hasher) Reset() {
+	h.d = *stackmurmur3.New128WithSeed(h.namespace.seeds())
+}
+
+// String returns u in the canonical 8-4-4-4-12 lowercase hex form.
+func (u UUID) String() string {
+	var b [36]byte
+	encode(b[:], u)
+	return string(b[:])
+}
+
+func encode(dst []byte, u UUID) {
+	hex.Encode(dst[0:8], u[0:4])
+	dst[8] = '-'
+	hex.Encode(dst[9:13], u[4:6])
+	dst[13] = '-'
+	hex.Encode(dst[14:18], u[6:8])
+	dst[18] = '-'
+	hex.Encode(dst[19:23], u[8:10])
+	dst[23] = '-'
+	hex.Encode(dst[24:], u[10:])
+}
+
+// Parse parses a UUID in the canonical form, in either case, optionally
+// wrapped in braces or prefixed with "urn:uuid:", or as 32 bare hex digits.
+func Parse(s string) (UUID, error) {
+	var u UUID
+	switch len(s) {
+	case 32:
+		if _, err := hex.Decode(u[:], []byte(s)); err != nil {
+			return Nil, ErrInvalid
+		}
+		return u, nil
+	case 36:
+	case 36 + 2:
+		if s[0] != '{' || s[37] != '}' {
+			return Nil, ErrInvalid
+		}
+		s = s[1:37]
+	case 36 + 9:
+		if s[:9] != "urn:uuid:" {
+			return Nil, ErrInvalid
+		}
+		s = s[9:]
+	default:
+		return Nil, ErrInvalid
+	}
+	if s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return Nil, ErrInvalid
+	}
+	for j, i := range hexOffsets {
+		hi, ok1 := fromHex(s[i])
+		lo, ok2 := fromHex(s[i+1])
+		if !ok1 || !ok2 {
+			return Nil, ErrInvalid
+		}
+		u[j] = hi<<4 | lo
+	}
+	return u, nil
+}
+
+// hexOffsets are the offsets of each byte's two hex digits in the canonical
+// form.
+var hexOffsets = [16]int{0, 2, 4, 6, 9, 11, 14, 16, 19, 21, 24, 26, 28, 30, 32, 34}
+
+func fromHex(c byte) (byte, bool) {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0', true
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10, true
+	case 'A' <= c && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
+
+// MustParse is like Parse but panics on error. It simplifies initializing
+// namespace variables.
+func MustParse(s string) UUID {
+	u, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return u
+}
+
+// MarshalText implements encoding.TextMarshaler with the canonical form.
+func (u UUID) MarshalText() ([]byte, error) {
+	b := make([]byte, 36)
+	encode(b, u)
+	return b, nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, accepting what Parse
+// accepts.
+func (u *UUID) UnmarshalText(text []byte) error {
+	v, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*u = v
+	return nil
+}
@@ -0,0 +1,117 @@
+package uuid
+
+import (
+	"encoding/json"
+	"testing"
+
+	murmur3 "github.com/m3db/stackmurmur3/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSum(t *testing.T) {
+	u := Sum(NamespaceDNS, []byte("example.com"))
+	assert.Equal(t, 8, u.Version())
+	assert.Equal(t, byte(0x80), u[8]&0xc0)
+	assert.Equal(t, u, SumString(NamespaceDNS, "example.com"))
+	assert.NotEqual(t, u, Sum(NamespaceURL, []byte("example.com")))
+	assert.NotEqual(t, u, Sum(NamespaceDNS, []byte("example.org")))
+
+	// Apart from the version and variant bits, the UUID is the big endian sum.
+	h1, h2 := murmur3.SeedSum128(0x6ba7b8109dad11d1, 0x80b400c04fd430c8, []byte("example.com"))
+	want := murmur3.Uint128{Hi: h1, Lo: h2}.Bytes()
+	want[6] = want[6]&0x0f | 0x80
+	want[8] = want[8]&0x3f | 0x80
+	assert.Equal(t, UUID(want), u)
+}
+
+func TestSumVersionBits(t *testing.T) {
+	for _, name := range []string{"", "a", "series:cpu.load{host=a}", "\xff\xff\xff\xff"} {
+		u := SumString(Nil, name)
+		assert.Equal(t, 8, u.Version(), name)
+		assert.Equal(t, byte(0x80), u[8]&0xc0, name)
+	}
+	assert.Equal(t, "ffffffff-ffff-8fff-bfff-ffffffffffff", FromSum128(^uint64(0), ^uint64(0)).String())
+	assert.Equal(t, "00000000-0000-8000-8000-000000000000", FromSum128(0, 0).String())
+}
+
+func TestHasher(t *testing.T) {
+	h := NewHasher(NamespaceURL)
+	h.Write([]byte("https://example.com/"))
+	h.Write([]byte("docs/"))
+	h.Write([]byte("index.html"))
+	want := SumString(NamespaceURL, "https://example.com/docs/index.html")
+	assert.Equal(t, want, h.UUID())
+
+	h.Reset()
+	assert.Equal(t, SumString(NamespaceURL, ""), h.UUID())
+
+	part := []byte("part")
+	allocs := testing.AllocsPerRun(100, func() {
+		h := NewHasher(NamespaceOID)
+		h.Write(part)
+		_ = h.UUID()
+	})
+	assert.Zero(t, allocs)
+}
+
+func TestParse(t *testing.T) {
+	u := SumString(NamespaceDNS, "example.com")
+	s := u.String()
+	require.Len(t, s, 36)
+
+	for _, in := range []string{
+		s,
+		"{" + s + "}",
+		"urn:uuid:" + s,
+		s[0:8] + s[9:13] + s[14:18] + s[19:23] + s[24:],
+		"6BA7B810-9DAD-11D1-80B4-00C04FD430C8",
+	} {
+		v, err := Parse(in)
+		require.NoError(t, err, in)
+		if in[0] == '6' {
+			assert.Equal(t, NamespaceDNS, v)
+		} else {
+			assert.Equal(t, u, v, in)
+		}
+	}
+
+	for _, in := range []string{
+		"",
+		s[:35],
+		s + "0",
+		"(" + s + ")",
+		"urn:uxid:" + s,
+		s[:8] + "+" + s[9:],
+		s[:35] + "g",
+		s[:9] + "x" + s[10:],
+		"0123456789abcdef0123456789abcdeg",
+	} {
+		_, err := Parse(in)
+		assert.Equal(t, ErrInvalid, err, in)
+	}
+	assert.Panics(t, func() { MustParse("nope") })
+}
+
+func TestMarshalText(t *testing.T) {
+	type doc struct {
+		ID UUID
+	}
+	in := doc{ID: SumString(NamespaceURL, "https://example.com/")}
+	b, err := json.Marshal(in)
+	require.NoError(t, err)
+	assert.Equal(t, `{"ID":"`+in.ID.String()+`"}`, string(b))
+
+	var out doc
+	require.NoError(t, json.Unmarshal(b, &out))
+	assert.Equal(t, in, out)
+	assert.Error(t, json.Unmarshal([]byte(`{"ID":"nope"}`), &out))
+}
+
+func BenchmarkSumString(b *testing.B) {
+	name := "series:cpu.load{host=a,region=us-east-1}"
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = SumString(NamespaceOID, name)
+	}
+}